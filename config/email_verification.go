@@ -0,0 +1,29 @@
+package config
+
+import (
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Policies for users who have not verified their email yet
+const (
+	VerificationPolicyNone   = "none"   // unverified users can do everything
+	VerificationPolicyLogin  = "login"  // unverified users can not log in
+	VerificationPolicyWrites = "writes" // unverified users can only read
+)
+
+type EmailVerificationConfig struct {
+	URL    string        // page of the client the verification token is appended to
+	Expiry time.Duration // lifetime of a verification token
+	Policy string        // none, login or writes
+}
+
+func NewEmailVerificationConfig() *EmailVerificationConfig {
+	godotenv.Load()
+	return &EmailVerificationConfig{
+		URL:    envString("EMAIL_VERIFICATION_URL", "http://localhost:8080/verify-email"),
+		Expiry: envDuration("EMAIL_VERIFICATION_EXPIRY", time.Hour*24),
+		Policy: envString("EMAIL_VERIFICATION_POLICY", VerificationPolicyNone),
+	}
+}
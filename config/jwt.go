@@ -12,14 +12,22 @@ type JWTConfig struct {
 	RefreshSecret string
 	AccessExpiry  time.Duration
 	RefreshExpiry time.Duration
+	// MFAExpiry is the time a user has to give their second factor after
+	// the password
+	MFAExpiry time.Duration
+	// OIDCStateExpiry is the time a user has to log in at the OpenID Connect
+	// provider
+	OIDCStateExpiry time.Duration
 }
 
 func NewJWTConfig() *JWTConfig {
 	godotenv.Load()
 	return &JWTConfig{
-		AccessSecret:  os.Getenv("ACCESS_TOKEN_SECRET"),
-		RefreshSecret: os.Getenv("REFRESH_TOKEN_SECRET"),
-		AccessExpiry:  time.Minute * 15,   //15 min
-		RefreshExpiry: time.Hour * 24 * 7, //7 days
+		AccessSecret:    os.Getenv("ACCESS_TOKEN_SECRET"),
+		RefreshSecret:   os.Getenv("REFRESH_TOKEN_SECRET"),
+		AccessExpiry:    time.Minute * 15,   //15 min
+		RefreshExpiry:   time.Hour * 24 * 7, //7 days
+		MFAExpiry:       time.Minute * 5,    //5 min
+		OIDCStateExpiry: time.Minute * 10,   //10 min
 	}
 }
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"github.com/joho/godotenv"
+)
+
+type MailConfig struct {
+	Driver       string // smtp, log or file
+	From         string // sender address of every email
+	FilePath     string // file the emails are appended to by the file driver
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+func NewMailConfig() *MailConfig {
+	godotenv.Load()
+	return &MailConfig{
+		Driver:       envString("MAIL_DRIVER", "log"),
+		From:         envString("MAIL_FROM", "no-reply@localhost"),
+		FilePath:     envString("MAIL_FILE_PATH", "mail.log"),
+		SMTPHost:     envString("SMTP_HOST", "localhost"),
+		SMTPPort:     envInt("SMTP_PORT", 587),
+		SMTPUsername: envString("SMTP_USERNAME", ""),
+		SMTPPassword: envString("SMTP_PASSWORD", ""),
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type MFAConfig struct {
+	Issuer      string        // name authenticator apps show next to the codes
+	MaxAttempts int           // wrong codes in a row before the second factor is locked
+	Lockout     time.Duration // time the second factor stays locked
+}
+
+func NewMFAConfig() *MFAConfig {
+	godotenv.Load()
+	return &MFAConfig{
+		Issuer:      envString("MFA_ISSUER", "RSS Aggregator"),
+		MaxAttempts: envInt("MFA_MAX_ATTEMPTS", 5),
+		Lockout:     envDuration("MFA_LOCKOUT", time.Minute*15),
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+type OIDCConfig struct {
+	IssuerURL    string   // issuer of the identity provider, empty disables SSO
+	ClientID     string   // client registered at the provider
+	ClientSecret string   // empty for public clients, which rely on PKCE alone
+	RedirectURL  string   // callback URL registered at the provider
+	Scopes       []string // scopes asked for, openid is always sent
+	// AutoProvision creates an account for provider users who have none,
+	// otherwise only existing users can log in through the provider
+	AutoProvision bool
+}
+
+func NewOIDCConfig() *OIDCConfig {
+	godotenv.Load()
+	return &OIDCConfig{
+		IssuerURL:     envString("OIDC_ISSUER_URL", ""),
+		ClientID:      envString("OIDC_CLIENT_ID", ""),
+		ClientSecret:  envString("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:   envString("OIDC_REDIRECT_URL", "http://localhost:8080/v1/auth/oidc/callback"),
+		Scopes:        strings.Fields(envString("OIDC_SCOPES", "openid email profile")),
+		AutoProvision: envBool("OIDC_AUTO_PROVISION", true),
+	}
+}
+
+func envBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type PasswordResetConfig struct {
+	URL    string        // page of the client the reset token is appended to
+	Expiry time.Duration // lifetime of a reset token
+}
+
+func NewPasswordResetConfig() *PasswordResetConfig {
+	godotenv.Load()
+	return &PasswordResetConfig{
+		URL:    envString("PASSWORD_RESET_URL", "http://localhost:8080/reset-password"),
+		Expiry: envDuration("PASSWORD_RESET_EXPIRY", time.Hour),
+	}
+}
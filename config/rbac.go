@@ -0,0 +1,18 @@
+package config
+
+import (
+	"github.com/joho/godotenv"
+)
+
+type RBACConfig struct {
+	// BootstrapAdminEmail is promoted to admin at startup when registered,
+	// it is how the first admin gets in
+	BootstrapAdminEmail string
+}
+
+func NewRBACConfig() *RBACConfig {
+	godotenv.Load()
+	return &RBACConfig{
+		BootstrapAdminEmail: envString("BOOTSTRAP_ADMIN_EMAIL", ""),
+	}
+}
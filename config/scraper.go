@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type ScraperConfig struct {
+	Concurrency  int           // number of feeds fetched at the same time
+	BatchSize    int           // number of feeds picked on every tick
+	Interval     time.Duration // time between two scraping rounds
+	FetchTimeout time.Duration // timeout for a single feed download
+}
+
+func NewScraperConfig() *ScraperConfig {
+	godotenv.Load()
+	return &ScraperConfig{
+		Concurrency:  envInt("SCRAPER_CONCURRENCY", 5),
+		BatchSize:    envInt("SCRAPER_BATCH_SIZE", 10),
+		Interval:     envDuration("SCRAPER_INTERVAL", time.Minute*10), //10 min
+		FetchTimeout: envDuration("SCRAPER_FETCH_TIMEOUT", time.Second*15),
+	}
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+type StorageConfig struct {
+	Driver     string // mongo, sqlite or memory
+	SQLitePath string // database file of the sqlite driver
+}
+
+func NewStorageConfig() *StorageConfig {
+	godotenv.Load()
+	return &StorageConfig{
+		Driver:     envString("STORAGE_DRIVER", "mongo"),
+		SQLitePath: envString("SQLITE_PATH", "rssagg.db"),
+	}
+}
+
+func envString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
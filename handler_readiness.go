@@ -4,17 +4,21 @@ import (
 	"context"
 	"net/http"
 	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
 )
 
-func handlerReadiness(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
-	defer cancel()
+func handlerReadiness(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
 
-	if err := MongoClient.Ping(ctx, nil); err != nil {
-		http.Error(w, "MongoDB not ready", http.StatusServiceUnavailable)
-		return
-	}
+		if err := store.Ping(ctx); err != nil {
+			http.Error(w, "Storage not ready", http.StatusServiceUnavailable)
+			return
+		}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("OK"))
-}
\ No newline at end of file
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	}
+}
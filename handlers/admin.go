@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/services"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+)
+
+// HandlerSetUserRoles replaces the roles of a user, admins can not change
+// their own roles so they can not lock everyone out
+func HandlerSetUserRoles(users storage.UserStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		admin, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		var req struct {
+			Email string   `json:"email"`
+			Roles []string `json:"roles"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Email == "" {
+			http.Error(w, "Email is required", http.StatusBadRequest)
+			return
+		}
+		if req.Email == admin.Email {
+			http.Error(w, "You can not change your own roles", http.StatusForbidden)
+			return
+		}
+		user, err := services.SetUserRoles(r.Context(), users, req.Email, req.Roles)
+		if err != nil {
+			switch err {
+			case services.ErrInvalidRole:
+				http.Error(w, "Invalid role", http.StatusBadRequest)
+			case storage.ErrNotFound:
+				http.Error(w, "User not found", http.StatusNotFound)
+			default:
+				log.Printf("Error setting roles: %v", err)
+				http.Error(w, "Failed to set roles", http.StatusInternalServerError)
+			}
+			return
+		}
+		log.Printf("👑 %s set the roles of %s to %v", admin.Email, user.Email, user.Roles)
+		utils.RespondWithJSON(w, http.StatusOK, user)
+	}
+}
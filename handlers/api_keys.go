@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/services"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+	"github.com/go-chi/chi"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// HandlerCreateAPIKey creates an API key of the user, the key is only in
+// this response
+func HandlerCreateAPIKey(apiKeys *services.APIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		var req struct {
+			Name      string     `json:"name"`
+			Scopes    []string   `json:"scopes"`
+			ExpiresAt *time.Time `json:"expires_at"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Name == "" {
+			http.Error(w, "Name is required", http.StatusBadRequest)
+			return
+		}
+		if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+			http.Error(w, "Expiry must be in the future", http.StatusBadRequest)
+			return
+		}
+		key, record, err := apiKeys.Create(r.Context(), user, req.Name, req.Scopes, req.ExpiresAt)
+		if err != nil {
+			if err == services.ErrInvalidScope {
+				http.Error(w, "Invalid scope, a key can only get scopes of the current token", http.StatusBadRequest)
+				return
+			}
+			log.Printf("Error creating API key: %v", err)
+			http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusCreated, map[string]any{
+			"key":     key,
+			"api_key": record,
+		})
+	}
+}
+
+func HandlerGetAPIKeys(apiKeys *services.APIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		keys, err := apiKeys.List(r.Context(), user.UserID)
+		if err != nil {
+			log.Printf("Error fetching API keys: %v", err)
+			http.Error(w, "Failed to fetch API keys", http.StatusInternalServerError)
+			return
+		}
+		if keys == nil {
+			keys = []models.APIKey{}
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"api_keys": keys,
+		})
+	}
+}
+
+func HandlerDeleteAPIKey(apiKeys *services.APIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+			return
+		}
+		if err := apiKeys.Revoke(r.Context(), user.UserID, id); err != nil {
+			if err == storage.ErrNotFound {
+				http.Error(w, "API key not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("Error revoking API key %s: %v", id.Hex(), err)
+			http.Error(w, "Failed to revoke API key", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message": "API key revoked successfully",
+		})
+	}
+}
@@ -2,14 +2,16 @@ package handlers
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
 
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
 	"github.com/Aym-Aymen777/RSS-Aggregator/services"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
 	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func HandlerRagisterUser(coll *mongo.Collection) http.HandlerFunc {
+func HandlerRagisterUser(users storage.UserStore, verifications *services.EmailVerificationService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Ensure the request method is POST
 		if r.Method != http.MethodPost {
@@ -36,11 +38,15 @@ func HandlerRagisterUser(coll *mongo.Collection) http.HandlerFunc {
 			http.Error(w, "Password must be at least 6 characters long", http.StatusBadRequest)
 			return
 		}
-		err := services.RegisterUser(coll, req.Username, req.Email, req.Password)
+		user, err := services.RegisterUser(users, req.Username, req.Email, req.Password)
 		if err != nil {
 			http.Error(w, "Failed to register user", http.StatusInternalServerError)
 			return
 		}
+		// the account exists even if the mail fails, the user can ask for a new link
+		if user != nil {
+			verifications.QueueVerification(user)
+		}
 		// Respond with a success message
 		w.WriteHeader(http.StatusCreated)
 		w.Write([]byte("User registered successfully"))
@@ -48,7 +54,11 @@ func HandlerRagisterUser(coll *mongo.Collection) http.HandlerFunc {
 	}
 }
 
-func HandlerLoginUser(coll *mongo.Collection, tokenService *services.TokenService) http.HandlerFunc {
+// HandlerLoginUser checks the password of the user. Users with a second factor
+// get an MFA challenge token to send with their code to HandlerLoginMFA
+// instead of the auth cookies. The optional scopes limit what the tokens of
+// the session can do, like for a read-only dashboard.
+func HandlerLoginUser(users storage.UserStore, tokenService *services.TokenService, verifications *services.EmailVerificationService, mfa *services.MFAService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Ensure the request method is POST
 		if r.Method != http.MethodPost {
@@ -57,8 +67,9 @@ func HandlerLoginUser(coll *mongo.Collection, tokenService *services.TokenServic
 		}
 		// Parse the request body
 		var req struct {
-			Email    string `json:"email"`
-			Password string `json:"password"`
+			Email    string   `json:"email"`
+			Password string   `json:"password"`
+			Scopes   []string `json:"scopes"`
 		}
 		decoder := json.NewDecoder(r.Body)
 		if err := decoder.Decode(&req); err != nil {
@@ -69,50 +80,63 @@ func HandlerLoginUser(coll *mongo.Collection, tokenService *services.TokenServic
 			http.Error(w, "Email and password are required", http.StatusBadRequest)
 			return
 		}
-		user, err := services.LoginUser(coll, req.Email, req.Password)
+		for _, scope := range req.Scopes {
+			if !models.ValidScope(scope) {
+				http.Error(w, "Invalid scope", http.StatusBadRequest)
+				return
+			}
+		}
+		user, err := services.LoginUser(users, req.Email, req.Password)
 		if err != nil {
 			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid email or password")
 			return
 		}
-		userID := user.ID
-		email := req.Email
-		// Generate Token Pair
-		tokens, err := tokenService.GenerateTokens(userID, email)
+		finishLogin(w, r, user, req.Scopes, tokenService, verifications, mfa)
+	}
+}
+
+// finishLogin logs in a user who proved who they are, with a password or at
+// an identity provider. Users with a second factor get an MFA challenge token
+// instead of the auth cookies.
+func finishLogin(w http.ResponseWriter, r *http.Request, user *models.Auth, scopes []string, tokenService *services.TokenService, verifications *services.EmailVerificationService, mfa *services.MFAService) {
+	if verifications.BlocksLogin(user) {
+		utils.RespondWithError(w, http.StatusForbidden, "Email address not verified")
+		return
+	}
+	mfaEnabled, err := mfa.Enabled(r.Context(), user.ID)
+	if err != nil {
+		log.Printf("Error checking two-factor authentication: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to log in")
+		return
+	}
+	if mfaEnabled {
+		challenge, err := tokenService.GenerateMFAChallenge(user.ID, user.Email, scopes)
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to generate tokens")
 			return
 		}
-
-		// Set access token cookie (short-lived)
-		http.SetCookie(w, &http.Cookie{
-			Name:     "access_token",
-			Value:    tokens.AccessToken,
-			Path:     "/",
-			MaxAge:   15 * 60,                 // 15 minutes in seconds
-			HttpOnly: true,                    // Prevents JavaScript access (XSS protection)
-			Secure:   false,                   //TODO Only sent over HTTPS (set to false in development)
-			SameSite: http.SameSiteStrictMode, // CSRF protection
-		})
-
-		// Set refresh token cookie (long-lived)
-		http.SetCookie(w, &http.Cookie{
-			Name:     "refresh_token",
-			Value:    tokens.RefreshToken,
-			Path:     "/",              // Only sent to refresh endpoint
-			MaxAge:   7 * 24 * 60 * 60, // 7 days in seconds
-			HttpOnly: true,
-			Secure:   false, //TODO Set to false in development
-			SameSite: http.SameSiteStrictMode,
-		})
-
 		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
-			"message": "Login successful",
-			"user":    user.Username,
+			"message":      "Two-factor code required",
+			"mfa_required": true,
+			"mfa_token":    challenge,
 		})
+		return
+	}
+	// Generate Token Pair in a new session of the client
+	tokens, err := tokenService.GenerateTokens(r.Context(), user, scopes, r.UserAgent(), clientIP(r))
+	if err != nil {
+		writeTokensError(w, err)
+		return
 	}
+	setAuthCookies(w, tokens)
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+		"message": "Login successful",
+		"user":    user.Username,
+	})
 }
 
-func HandlerRefreshToken(coll *mongo.Collection, tokenService *services.TokenService) http.HandlerFunc {
+func HandlerRefreshToken(users storage.UserStore, tokenService *services.TokenService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Ensure the request method is POST
 		if r.Method != http.MethodPost {
@@ -132,46 +156,106 @@ func HandlerRefreshToken(coll *mongo.Collection, tokenService *services.TokenSer
 			http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
 			return
 		}
-		userID := claims.UserID
-		email := claims.Email
-		// Generate new token pair
-		tokens, err := tokenService.GenerateTokens(userID, email)
+		// the access token gets the current email and roles of the user
+		user, err := users.GetUserByID(r.Context(), claims.UserID)
 		if err != nil {
-			http.Error(w, "Failed to generate tokens", http.StatusInternalServerError)
+			http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
 			return
 		}
-		http.SetCookie(w, &http.Cookie{
-			Name:     "refresh_token",
-			Value:    "",
-			Path:     "/",
-			MaxAge:   -1,
-			HttpOnly: true,
+		// Rotate the refresh token and generate a new token pair
+		tokens, err := tokenService.RotateTokens(r.Context(), claims, user)
+		if err != nil {
+			switch err {
+			case services.ErrRefreshTokenReused:
+				log.Printf("🔒 Refresh token reuse detected for user %s, revoking its session", user.ID.Hex())
+				clearAuthCookies(w)
+				http.Error(w, "Refresh token reuse detected", http.StatusUnauthorized)
+			case services.ErrInvalidRefreshToken:
+				clearAuthCookies(w)
+				http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+			default:
+				http.Error(w, "Failed to generate tokens", http.StatusInternalServerError)
+			}
+			return
+		}
+		setAuthCookies(w, tokens)
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message": "Token refreshed successfully",
 		})
-		// Set new access token cookie
-		http.SetCookie(w, &http.Cookie{
-			Name:  "access_token",
-			Value: tokens.AccessToken,
+	}
+}
 
-			Path:     "/",
-			MaxAge:   15 * 60, // 15 minutes in seconds
-			HttpOnly: true,
-			Secure:   false, //TODO Only sent over HTTPS (set to false in development)
-			SameSite: http.SameSiteStrictMode,
+// HandlerLogout revokes the session of the refresh token and clears both
+// cookies. It does not require a valid access token, so a client whose access
+// token expired can still log out.
+func HandlerLogout(tokenService *services.TokenService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Ensure the request method is POST
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		// an invalid or expired refresh token has nothing left to revoke
+		if cookie, err := r.Cookie("refresh_token"); err == nil {
+			if claims, err := tokenService.ValidateRefreshToken(cookie.Value); err == nil {
+				if err := revokeRefreshSession(r, tokenService, claims); err != nil {
+					log.Printf("Error revoking session: %v", err)
+					http.Error(w, "Failed to log out", http.StatusInternalServerError)
+					return
+				}
+			}
+		}
+		clearAuthCookies(w)
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message": "Logged out successfully",
 		})
+	}
+}
 
-		// Set new refresh token cookie
-		http.SetCookie(w, &http.Cookie{
-			Name:  "refresh_token",
-			Value: tokens.RefreshToken,
+func setAuthCookies(w http.ResponseWriter, tokens *models.TokenResponse) {
+	// Set access token cookie (short-lived)
+	http.SetCookie(w, &http.Cookie{
+		Name:     "access_token",
+		Value:    tokens.AccessToken,
+		Path:     "/",
+		MaxAge:   15 * 60,                 // 15 minutes in seconds
+		HttpOnly: true,                    // Prevents JavaScript access (XSS protection)
+		Secure:   false,                   //TODO Only sent over HTTPS (set to false in development)
+		SameSite: http.SameSiteStrictMode, // CSRF protection
+	})
+
+	// Set refresh token cookie (long-lived)
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    tokens.RefreshToken,
+		Path:     "/",
+		MaxAge:   7 * 24 * 60 * 60, // 7 days in seconds
+		HttpOnly: true,
+		Secure:   false, //TODO Set to false in development
+		SameSite: http.SameSiteStrictMode,
+	})
+}
 
+func clearAuthCookies(w http.ResponseWriter) {
+	for _, name := range []string{"access_token", "refresh_token"} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
 			Path:     "/",
-			MaxAge:   7 * 24 * 60 * 60, // 7 days in seconds
+			MaxAge:   -1,
 			HttpOnly: true,
-			Secure:   false, //TODO Set to false in development
+			Secure:   false, //TODO Only sent over HTTPS (set to false in development)
 			SameSite: http.SameSiteStrictMode,
 		})
-		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
-			"message": "Token refreshed successfully",
-		})
 	}
 }
+
+// writeTokensError responds to a failed GenerateTokens, the requested scopes
+// can be more than the roles of the user allow
+func writeTokensError(w http.ResponseWriter, err error) {
+	if err == services.ErrInvalidScope {
+		utils.RespondWithError(w, http.StatusForbidden, "Requested scopes are not allowed for this user")
+		return
+	}
+	utils.RespondWithError(w, http.StatusInternalServerError, "Failed to generate tokens")
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/services"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+)
+
+// HandlerRequestEmailVerification mails a new verification link to the email.
+// It answers the same whether the email is registered or not.
+func HandlerRequestEmailVerification(verifications *services.EmailVerificationService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Ensure the request method is POST
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Email string `json:"email"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Email == "" {
+			http.Error(w, "Email is required", http.StatusBadRequest)
+			return
+		}
+		// a failure is only logged, the response must not depend on the email
+		if err := verifications.ResendVerification(r.Context(), req.Email); err != nil {
+			log.Printf("Error requesting email verification: %v", err)
+		}
+		utils.RespondWithJSON(w, http.StatusAccepted, map[string]any{
+			"message": "If the email is registered and not verified yet, a verification link has been sent",
+		})
+	}
+}
+
+// HandlerConfirmEmailVerification verifies the email a token was mailed to
+func HandlerConfirmEmailVerification(verifications *services.EmailVerificationService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Ensure the request method is POST
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Token string `json:"token"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Token == "" {
+			http.Error(w, "Token is required", http.StatusBadRequest)
+			return
+		}
+		if err := verifications.VerifyEmail(r.Context(), req.Token); err != nil {
+			if err == services.ErrInvalidVerificationToken {
+				http.Error(w, "Invalid or expired verification token", http.StatusBadRequest)
+				return
+			}
+			log.Printf("Error verifying email: %v", err)
+			http.Error(w, "Failed to verify email", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message": "Email verified successfully",
+		})
+	}
+}
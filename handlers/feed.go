@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+	"github.com/go-chi/chi"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func HandlerCreateFeed(feeds storage.FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Ensure the request method is POST
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		// get the fields from the client
+		var req struct {
+			URL      string `json:"url"`
+			Title    string `json:"title"`
+			SiteLink string `json:"site_link"`
+			Category string `json:"category"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.URL == "" {
+			http.Error(w, "Missing required fields", http.StatusBadRequest)
+			return
+		}
+		if !isValidFeedURL(req.URL) {
+			http.Error(w, "Invalid feed URL", http.StatusBadRequest)
+			return
+		}
+		// a user can only subscribe to the same url once
+		_, err := feeds.GetFeedByURL(r.Context(), user.UserID, req.URL)
+		if err == nil {
+			http.Error(w, "Feed already exists", http.StatusConflict)
+			return
+		}
+		if err != storage.ErrNotFound {
+			http.Error(w, "Failed to create feed", http.StatusInternalServerError)
+			return
+		}
+		feed := models.Feed{
+			URL:       req.URL,
+			Title:     req.Title,
+			SiteLink:  req.SiteLink,
+			Category:  req.Category,
+			UserID:    user.UserID,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := feeds.CreateFeed(r.Context(), &feed); err != nil {
+			log.Printf("Error creating feed: %v", err)
+			http.Error(w, "Failed to create feed", http.StatusInternalServerError)
+			return
+		}
+		// the creator of a feed follows it
+		if _, err := feeds.FollowFeed(r.Context(), user.UserID, feed.ID); err != nil {
+			log.Printf("Error following feed: %v", err)
+			http.Error(w, "Failed to follow feed", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusCreated, feed)
+	}
+}
+
+func HandlerGetFeeds(feeds storage.FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		//force get methode
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		//get the feeds of the current user
+		userFeeds, err := feeds.ListFeedsByUser(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Failed to fetch feeds", http.StatusInternalServerError)
+			return
+		}
+		if userFeeds == nil {
+			userFeeds = []models.Feed{}
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"feeds": userFeeds,
+		})
+	}
+}
+
+func HandlerGetFeedByID(feeds storage.FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		//force get methode
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		//get the id from the url
+		id := chi.URLParam(r, "id")
+		if id == "" {
+			http.Error(w, "Missing feed ID", http.StatusBadRequest)
+			return
+		}
+		objectID, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+			return
+		}
+		feed, ok := findUserFeed(w, r, feeds, objectID, user)
+		if !ok {
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, feed)
+	}
+}
+
+func HandlerDeleteFeed(feeds storage.FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		//force delete methode
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		//get the id from the url
+		id := chi.URLParam(r, "id")
+		if id == "" {
+			http.Error(w, "Missing feed ID", http.StatusBadRequest)
+			return
+		}
+		objectID, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+			return
+		}
+		feed, ok := findUserFeed(w, r, feeds, objectID, user)
+		if !ok {
+			return
+		}
+		// deleting the feed would take its posts away from everyone who follows it
+		followed, err := feeds.HasOtherFollowers(r.Context(), objectID, feed.UserID)
+		if err != nil {
+			log.Printf("Error checking followers of feed %s: %v", id, err)
+			http.Error(w, "Failed to delete feed", http.StatusInternalServerError)
+			return
+		}
+		if followed {
+			http.Error(w, "Feed is followed by other users, unfollow it instead", http.StatusConflict)
+			return
+		}
+		//delete the feed with its follows and posts
+		if err := feeds.DeleteFeed(r.Context(), objectID); err != nil {
+			if err == storage.ErrNotFound {
+				http.Error(w, "Feed not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("Error deleting feed %s: %v", id, err)
+			http.Error(w, "Failed to delete feed", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message": "Feed deleted successfully",
+		})
+	}
+}
+
+// findUserFeed gets a feed added by the user, or any feed for an admin. It
+// writes the error response and returns false when the feed does not exist or
+// belongs to someone else.
+func findUserFeed(w http.ResponseWriter, r *http.Request, feeds storage.FeedStore, id bson.ObjectID, user *models.AccessTokenClaims) (*models.Feed, bool) {
+	feed, err := feeds.GetFeed(r.Context(), id)
+	if err == storage.ErrNotFound || (err == nil && feed.UserID != user.UserID && !user.HasRole(models.RoleAdmin)) {
+		http.Error(w, "Feed not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error fetching feed: %v", err)
+		http.Error(w, "Failed to fetch feed", http.StatusInternalServerError)
+		return nil, false
+	}
+	return feed, true
+}
+
+func isValidFeedURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+	"github.com/go-chi/chi"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func HandlerCreateFeedFollow(feeds storage.FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Ensure the request method is POST
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		var req struct {
+			FeedID string `json:"feed_id"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		feedID, err := bson.ObjectIDFromHex(req.FeedID)
+		if err != nil {
+			http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+			return
+		}
+		if _, err := feeds.GetFeed(r.Context(), feedID); err != nil {
+			if err == storage.ErrNotFound {
+				http.Error(w, "Feed not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to follow feed", http.StatusInternalServerError)
+			return
+		}
+		follow, err := feeds.FollowFeed(r.Context(), user.UserID, feedID)
+		if err != nil {
+			log.Printf("Error following feed: %v", err)
+			http.Error(w, "Failed to follow feed", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusCreated, follow)
+	}
+}
+
+func HandlerGetFeedFollows(feeds storage.FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		//force get methode
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		follows, err := feeds.ListFollows(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Failed to fetch feed follows", http.StatusInternalServerError)
+			return
+		}
+		if follows == nil {
+			follows = []models.FeedFollow{}
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"feed_follows": follows,
+		})
+	}
+}
+
+func HandlerDeleteFeedFollow(feeds storage.FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		//force delete methode
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		//get the id from the url
+		id := chi.URLParam(r, "id")
+		objectID, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			http.Error(w, "Invalid feed follow ID", http.StatusBadRequest)
+			return
+		}
+		if err := feeds.DeleteFollow(r.Context(), user.UserID, objectID); err != nil {
+			if err == storage.ErrNotFound {
+				http.Error(w, "Feed follow not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to delete feed follow", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message": "Feed unfollowed successfully",
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/memory"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestFeedFollows(t *testing.T) {
+	store := memory.New()
+	alice, bob := testUser(), testUser()
+	feed := createFeed(t, store, bob.UserID)
+	post := createPost(t, store, models.Post{Title: "from the feed", FeedID: feed.ID})
+
+	follow := func(user *models.AccessTokenClaims, feedID string) *httptest.ResponseRecorder {
+		return serve(t, http.MethodPost, "/feed_follows", HandlerCreateFeedFollow(store), "/feed_follows", user, map[string]string{"feed_id": feedID})
+	}
+	listFollows := func(user *models.AccessTokenClaims) []models.FeedFollow {
+		w := serve(t, http.MethodGet, "/feed_follows", HandlerGetFeedFollows(store), "/feed_follows", user, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET /feed_follows: status %d: %s", w.Code, w.Body)
+		}
+		return decode[struct {
+			FeedFollows []models.FeedFollow `json:"feed_follows"`
+		}](t, w).FeedFollows
+	}
+	unfollow := func(user *models.AccessTokenClaims, id bson.ObjectID) int {
+		return serve(t, http.MethodDelete, "/feed_follows/{id}", HandlerDeleteFeedFollow(store), "/feed_follows/"+id.Hex(), user, nil).Code
+	}
+
+	if w := follow(alice, bson.NewObjectID().Hex()); w.Code != http.StatusNotFound {
+		t.Errorf("follow of a missing feed: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w := follow(alice, "nope"); w.Code != http.StatusBadRequest {
+		t.Errorf("follow with an invalid ID: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if follows := listFollows(alice); len(follows) != 0 {
+		t.Fatalf("alice follows %v before following anything", follows)
+	}
+	if page := getPosts(t, store, alice, ""); len(page.Posts) != 0 {
+		t.Fatalf("alice sees %d posts before following the feed", len(page.Posts))
+	}
+
+	w := follow(alice, feed.ID.Hex())
+	if w.Code != http.StatusCreated {
+		t.Fatalf("follow: status %d: %s", w.Code, w.Body)
+	}
+	created := decode[models.FeedFollow](t, w)
+	if created.FeedID != feed.ID || created.UserID != alice.UserID {
+		t.Errorf("follow = %+v, want alice following %s", created, feed.ID)
+	}
+	if w := follow(alice, feed.ID.Hex()); w.Code != http.StatusCreated || decode[models.FeedFollow](t, w).ID != created.ID {
+		t.Errorf("following twice did not return the existing follow")
+	}
+	if follows := listFollows(alice); len(follows) != 1 || follows[0].ID != created.ID {
+		t.Errorf("alice follows %v, want only %s", follows, created.ID)
+	}
+	if follows := listFollows(bob); len(follows) != 0 {
+		t.Errorf("bob follows %v, want nothing", follows)
+	}
+	if page := getPosts(t, store, alice, ""); len(page.Posts) != 1 || page.Posts[0].ID != post.ID {
+		t.Errorf("alice sees %v after following, want the post of the feed", postIDs(page.Posts))
+	}
+
+	if code := unfollow(bob, created.ID); code != http.StatusNotFound {
+		t.Errorf("unfollow by another user: status %d, want %d", code, http.StatusNotFound)
+	}
+	if code := unfollow(alice, created.ID); code != http.StatusOK {
+		t.Errorf("unfollow: status %d, want %d", code, http.StatusOK)
+	}
+	if follows := listFollows(alice); len(follows) != 0 {
+		t.Errorf("alice still follows %v", follows)
+	}
+	if page := getPosts(t, store, alice, ""); len(page.Posts) != 0 {
+		t.Errorf("alice still sees %d posts after unfollowing", len(page.Posts))
+	}
+}
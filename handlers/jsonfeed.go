@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+)
+
+// jsonFeedMaxItems is the number of posts rendered in the JSON Feed output
+const jsonFeedMaxItems = 100
+
+func HandlerGetPostsJSONFeed(store storage.PostStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		//force get methode
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		//get the latest posts the user can read
+		posts, err := store.ListPosts(r.Context(), user.UserID, storage.PostListQuery{Limit: jsonFeedMaxItems})
+		if err != nil {
+			log.Printf("Error fetching posts: %v", err)
+			http.Error(w, "Failed to fetch posts", http.StatusInternalServerError)
+			return
+		}
+
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		feed := models.JSONFeed{
+			Version: models.JSONFeedVersion,
+			Title:   "RSS Aggregator - " + user.Email,
+			FeedURL: scheme + "://" + r.Host + r.URL.Path,
+			Items:   make([]models.JSONFeedItem, 0, len(posts)),
+		}
+		for _, post := range posts {
+			feed.Items = append(feed.Items, postToJSONFeedItem(post))
+		}
+		utils.RespondWithJSONFeed(w, http.StatusOK, feed)
+	}
+}
+
+func postToJSONFeedItem(post models.Post) models.JSONFeedItem {
+	item := models.JSONFeedItem{
+		ID:    post.ID.Hex(),
+		URL:   post.Link,
+		Title: post.Title,
+		Tags:  post.Categories,
+	}
+	// an item needs content_html or content_text, the description is the
+	// content when the feed did not provide a full one
+	if post.Content != "" {
+		item.ContentHTML = post.Content
+		item.Summary = post.Description
+	} else {
+		item.ContentHTML = post.Description
+	}
+	if !post.PublishedAt.IsZero() {
+		item.DatePublished = post.PublishedAt.Format(time.RFC3339)
+	}
+	if !post.UpdatedAt.IsZero() {
+		item.DateModified = post.UpdatedAt.Format(time.RFC3339)
+	}
+	if post.Author != "" {
+		item.Authors = []models.JSONFeedAuthor{{Name: post.Author}}
+	}
+	if post.Enclosure != nil {
+		mimeType := post.Enclosure.Type
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		item.Attachments = []models.JSONFeedAttachment{{
+			URL:         post.Enclosure.URL,
+			MimeType:    mimeType,
+			SizeInBytes: post.Enclosure.Length,
+		}}
+	}
+	return item
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/services"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// HandlerLoginMFA finishes a login with the MFA challenge token of the
+// password step and a TOTP or recovery code
+func HandlerLoginMFA(users storage.UserStore, tokenService *services.TokenService, mfa *services.MFAService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Ensure the request method is POST
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			MFAToken string `json:"mfa_token"`
+			Code     string `json:"code"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.MFAToken == "" || req.Code == "" {
+			http.Error(w, "Missing required fields", http.StatusBadRequest)
+			return
+		}
+		claims, err := tokenService.ValidateMFAChallenge(req.MFAToken)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired MFA token")
+			return
+		}
+		if !verifyMFACode(w, r, mfa, claims.UserID, req.Code) {
+			return
+		}
+		user, err := users.GetUserByID(r.Context(), claims.UserID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired MFA token")
+			return
+		}
+		tokens, err := tokenService.GenerateTokens(r.Context(), user, claims.Scopes, r.UserAgent(), clientIP(r))
+		if err != nil {
+			writeTokensError(w, err)
+			return
+		}
+		setAuthCookies(w, tokens)
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message": "Login successful",
+			"user":    user.Username,
+		})
+	}
+}
+
+// HandlerEnrollMFA starts the two-factor enrollment of the user, the secret
+// is added to an authenticator app and confirmed with HandlerConfirmMFA
+func HandlerEnrollMFA(mfa *services.MFAService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		secret, uri, err := mfa.Enroll(r.Context(), user.UserID, user.Email)
+		if err != nil {
+			if err == services.ErrMFAAlreadyEnabled {
+				http.Error(w, "Two-factor authentication is already enabled", http.StatusConflict)
+				return
+			}
+			log.Printf("Error enrolling two-factor authentication: %v", err)
+			http.Error(w, "Failed to enroll two-factor authentication", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"secret":      secret,
+			"otpauth_uri": uri,
+		})
+	}
+}
+
+// HandlerConfirmMFA enables the enrolled second factor with a first code and
+// returns the recovery codes, the only time they are shown
+func HandlerConfirmMFA(mfa *services.MFAService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		var req struct {
+			Code string `json:"code"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Code == "" {
+			http.Error(w, "Code is required", http.StatusBadRequest)
+			return
+		}
+		codes, err := mfa.Confirm(r.Context(), user.UserID, req.Code)
+		if err != nil {
+			switch err {
+			case services.ErrInvalidMFACode:
+				http.Error(w, "Invalid code", http.StatusBadRequest)
+			case services.ErrMFANotEnrolled:
+				http.Error(w, "Two-factor enrollment not started", http.StatusBadRequest)
+			case services.ErrMFAAlreadyEnabled:
+				http.Error(w, "Two-factor authentication is already enabled", http.StatusConflict)
+			default:
+				log.Printf("Error confirming two-factor authentication: %v", err)
+				http.Error(w, "Failed to enable two-factor authentication", http.StatusInternalServerError)
+			}
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message":        "Two-factor authentication enabled",
+			"recovery_codes": codes,
+		})
+	}
+}
+
+// HandlerDisableMFA removes the second factor after checking the password and
+// a code of the user again
+func HandlerDisableMFA(mfa *services.MFAService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		var req struct {
+			Password string `json:"password"`
+			Code     string `json:"code"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		// the password is checked by Disable, accounts without one only give a code
+		if req.Code == "" {
+			http.Error(w, "Missing required fields", http.StatusBadRequest)
+			return
+		}
+		if err := mfa.Disable(r.Context(), user.UserID, req.Password, req.Code); err != nil {
+			switch err {
+			case services.ErrInvalidPassword:
+				utils.RespondWithError(w, http.StatusUnauthorized, "Invalid password")
+			case services.ErrMFANotEnabled:
+				http.Error(w, "Two-factor authentication is not enabled", http.StatusBadRequest)
+			case services.ErrInvalidMFACode, services.ErrMFALocked:
+				writeMFAError(w, err)
+			default:
+				log.Printf("Error disabling two-factor authentication: %v", err)
+				http.Error(w, "Failed to disable two-factor authentication", http.StatusInternalServerError)
+			}
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message": "Two-factor authentication disabled",
+		})
+	}
+}
+
+// verifyMFACode checks a code of the user, it writes the error response and
+// returns false when the code is not accepted
+func verifyMFACode(w http.ResponseWriter, r *http.Request, mfa *services.MFAService, userID bson.ObjectID, code string) bool {
+	err := mfa.Verify(r.Context(), userID, code)
+	switch err {
+	case nil:
+		return true
+	case services.ErrInvalidMFACode, services.ErrMFALocked:
+		writeMFAError(w, err)
+	case services.ErrMFANotEnabled:
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired MFA token")
+	default:
+		log.Printf("Error verifying two-factor code: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to verify code")
+	}
+	return false
+}
+
+func writeMFAError(w http.ResponseWriter, err error) {
+	if err == services.ErrMFALocked {
+		utils.RespondWithError(w, http.StatusTooManyRequests, "Too many invalid codes, try again later")
+		return
+	}
+	utils.RespondWithError(w, http.StatusUnauthorized, "Invalid code")
+}
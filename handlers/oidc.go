@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/services"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+)
+
+// oidcStateCookie keeps the state of an OpenID Connect login in the browser
+// that started it, only the callback reads it
+const (
+	oidcStateCookie = "oidc_state"
+	oidcStatePath   = "/v1/auth/oidc"
+)
+
+// HandlerOIDCLogin sends the user to the identity provider to log in, the
+// provider sends them back to HandlerOIDCCallback
+func HandlerOIDCLogin(oidcService *services.OIDCService, tokenService *services.TokenService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		authURL, stateToken, err := oidcService.Begin(r.Context())
+		if err != nil {
+			log.Printf("Error starting OIDC login: %v", err)
+			utils.RespondWithError(w, http.StatusBadGateway, "Identity provider unavailable")
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     oidcStateCookie,
+			Value:    stateToken,
+			Path:     oidcStatePath,
+			MaxAge:   int(tokenService.OIDCStateExpiry().Seconds()),
+			HttpOnly: true,
+			Secure:   false, //TODO Only sent over HTTPS (set to false in development)
+			// the provider redirects back from another site, a strict cookie
+			// would not be sent with the callback
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, authURL, http.StatusFound)
+	}
+}
+
+// HandlerOIDCCallback finishes the login at the identity provider and logs
+// the user in like HandlerLoginUser does
+func HandlerOIDCCallback(oidcService *services.OIDCService, tokenService *services.TokenService, verifications *services.EmailVerificationService, mfa *services.MFAService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		// the state can only be used once, whatever the outcome
+		http.SetCookie(w, &http.Cookie{
+			Name:     oidcStateCookie,
+			Value:    "",
+			Path:     oidcStatePath,
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   false, //TODO Only sent over HTTPS (set to false in development)
+			SameSite: http.SameSiteLaxMode,
+		})
+		query := r.URL.Query()
+		if providerError := query.Get("error"); providerError != "" {
+			log.Printf("OIDC provider returned an error: %s %s", providerError, query.Get("error_description"))
+			utils.RespondWithError(w, http.StatusUnauthorized, "Login at the identity provider failed")
+			return
+		}
+		cookie, err := r.Cookie(oidcStateCookie)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Login state not found, start the login again")
+			return
+		}
+		user, err := oidcService.Complete(r.Context(), cookie.Value, query.Get("state"), query.Get("code"))
+		if err != nil {
+			switch err {
+			case services.ErrInvalidOIDCState:
+				utils.RespondWithError(w, http.StatusBadRequest, "Invalid or expired login state, start the login again")
+			case services.ErrOIDCLoginFailed:
+				utils.RespondWithError(w, http.StatusUnauthorized, "Login at the identity provider failed")
+			case services.ErrOIDCEmailNotVerified:
+				utils.RespondWithError(w, http.StatusForbidden, "The identity provider has not verified your email")
+			case services.ErrOIDCNoAccount:
+				utils.RespondWithError(w, http.StatusForbidden, "No account for this user")
+			default:
+				log.Printf("Error completing OIDC login: %v", err)
+				utils.RespondWithError(w, http.StatusInternalServerError, "Failed to log in")
+			}
+			return
+		}
+		finishLogin(w, r, user, nil, tokenService, verifications, mfa)
+	}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/opml"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// maxOPMLSize caps the size of an uploaded OPML file (5 MB)
+const maxOPMLSize = 5 << 20
+
+// opmlImportResult reports what happened to one outline of the imported file
+type opmlImportResult struct {
+	Title    string `json:"title"`
+	XMLURL   string `json:"xml_url"`
+	Category string `json:"category,omitempty"`
+	Status   string `json:"status"` // created, followed, skipped or invalid
+	Reason   string `json:"reason,omitempty"`
+}
+
+func HandlerImportOPML(feeds storage.FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Ensure the request method is POST
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		// the file is either a multipart "file" field or the raw request body
+		r.Body = http.MaxBytesReader(w, r.Body, maxOPMLSize)
+		var body io.Reader = r.Body
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, "Missing OPML file", http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+			body = file
+		}
+		doc, err := opml.Parse(body)
+		if err != nil {
+			http.Error(w, "Invalid OPML file", http.StatusBadRequest)
+			return
+		}
+
+		// urls the user already follows, and the ones seen earlier in the file
+		existing, err := feeds.ListFollowedFeeds(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Failed to fetch feeds", http.StatusInternalServerError)
+			return
+		}
+		known := make(map[string]bool, len(existing))
+		for _, feed := range existing {
+			known[feed.URL] = true
+		}
+
+		results := []opmlImportResult{}
+		counts := map[string]int{"created": 0, "followed": 0, "skipped": 0, "invalid": 0}
+		for _, sub := range doc.Subscriptions() {
+			result := opmlImportResult{Title: sub.Title, XMLURL: sub.XMLURL, Category: sub.Category}
+			switch {
+			case !isValidFeedURL(sub.XMLURL):
+				result.Status, result.Reason = "invalid", "missing or invalid xmlUrl"
+			case known[sub.XMLURL]:
+				result.Status, result.Reason = "skipped", "already subscribed"
+			default:
+				result.Status, result.Reason = importSubscription(r, feeds, user.UserID, sub)
+				if result.Status != "invalid" {
+					known[sub.XMLURL] = true
+				}
+			}
+			counts[result.Status]++
+			results = append(results, result)
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"created":  counts["created"],
+			"followed": counts["followed"],
+			"skipped":  counts["skipped"],
+			"invalid":  counts["invalid"],
+			"results":  results,
+		})
+	}
+}
+
+// importSubscription follows the feed of the url when another user already
+// added it, and creates it otherwise
+func importSubscription(r *http.Request, feeds storage.FeedStore, userID bson.ObjectID, sub opml.Subscription) (status, reason string) {
+	feed, err := feeds.FindFeedByURL(r.Context(), sub.XMLURL)
+	switch {
+	case err == nil:
+		status = "followed"
+	case err == storage.ErrNotFound:
+		feed = &models.Feed{
+			URL:       sub.XMLURL,
+			Title:     sub.Title,
+			SiteLink:  sub.HTMLURL,
+			Category:  sub.Category,
+			UserID:    userID,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		err = feeds.CreateFeed(r.Context(), feed)
+		status = "created"
+	}
+	if err == nil {
+		_, err = feeds.FollowFeed(r.Context(), userID, feed.ID)
+	}
+	if err != nil {
+		log.Printf("Error importing feed %s: %v", sub.XMLURL, err)
+		return "invalid", "failed to save feed"
+	}
+	return status, ""
+}
+
+func HandlerExportOPML(feeds storage.FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		//force get methode
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		//the subscriptions of the user are the feeds they follow
+		userFeeds, err := feeds.ListFollowedFeeds(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Failed to fetch feeds", http.StatusInternalServerError)
+			return
+		}
+		sort.SliceStable(userFeeds, func(i, j int) bool {
+			if userFeeds[i].Category != userFeeds[j].Category {
+				return userFeeds[i].Category < userFeeds[j].Category
+			}
+			return userFeeds[i].Title < userFeeds[j].Title
+		})
+		subs := make([]opml.Subscription, 0, len(userFeeds))
+		for _, feed := range userFeeds {
+			title := feed.Title
+			if title == "" {
+				title = feed.URL
+			}
+			subs = append(subs, opml.Subscription{
+				Title:    title,
+				XMLURL:   feed.URL,
+				HTMLURL:  feed.SiteLink,
+				Category: feed.Category,
+			})
+		}
+		doc := opml.New("RSS Aggregator subscriptions of "+user.Email, subs)
+		doc.Head.OwnerEmail = user.Email
+
+		w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="subscriptions.opml"`)
+		w.WriteHeader(http.StatusOK)
+		if err := doc.Write(w); err != nil {
+			log.Printf("Error writing OPML export: %v", err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/opml"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/memory"
+)
+
+func TestImportOPMLFollowsExistingFeeds(t *testing.T) {
+	store := memory.New()
+	alice, bob := testUser(), testUser()
+	shared := createFeed(t, store, alice.UserID)
+	followed := createFeed(t, store, alice.UserID)
+	if _, err := store.FollowFeed(context.Background(), bob.UserID, followed.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	file := `<opml version="2.0"><body>
+		<outline text="Shared" xmlUrl="` + shared.URL + `"/>
+		<outline text="Followed" xmlUrl="` + followed.URL + `"/>
+		<outline text="Tech"><outline text="New" xmlUrl="https://example.com/new.xml"/></outline>
+		<outline text="New again" xmlUrl="https://example.com/new.xml"/>
+		<outline text="Broken" xmlUrl="ftp://example.com/feed"/>
+	</body></opml>`
+	w := serve(t, http.MethodPost, "/opml", HandlerImportOPML(store), "/opml", bob, file)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import: status %d: %s", w.Code, w.Body)
+	}
+	got := decode[struct {
+		Results []opmlImportResult `json:"results"`
+	}](t, w)
+	var statuses []string
+	for _, result := range got.Results {
+		statuses = append(statuses, result.Status)
+	}
+	if want := []string{"followed", "skipped", "created", "skipped", "invalid"}; !slices.Equal(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+
+	// the shared feed was followed, not created a second time
+	for _, url := range []string{shared.URL, "https://example.com/new.xml"} {
+		count := 0
+		for _, user := range []*models.AccessTokenClaims{alice, bob} {
+			feeds, _ := store.ListFeedsByUser(context.Background(), user.UserID)
+			for _, feed := range feeds {
+				if feed.URL == url {
+					count++
+				}
+			}
+		}
+		if count != 1 {
+			t.Errorf("%d feeds with the url %s, want 1", count, url)
+		}
+	}
+
+	w = serve(t, http.MethodGet, "/opml", HandlerExportOPML(store), "/opml", bob, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export: status %d: %s", w.Code, w.Body)
+	}
+	doc, err := opml.Parse(strings.NewReader(w.Body.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for _, sub := range doc.Subscriptions() {
+		urls = append(urls, sub.XMLURL)
+	}
+	slices.Sort(urls)
+	want := []string{shared.URL, followed.URL, "https://example.com/new.xml"}
+	slices.Sort(want)
+	if !slices.Equal(urls, want) {
+		t.Errorf("export of bob = %v, want the feeds bob follows %v", urls, want)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/services"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+)
+
+// HandlerRequestPasswordReset mails a reset link to the email. It answers the
+// same whether the email is registered or not.
+func HandlerRequestPasswordReset(resets *services.PasswordResetService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Ensure the request method is POST
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Email string `json:"email"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Email == "" {
+			http.Error(w, "Email is required", http.StatusBadRequest)
+			return
+		}
+		// a failure is only logged, the response must not depend on the email
+		if err := resets.RequestReset(r.Context(), req.Email); err != nil {
+			log.Printf("Error requesting password reset: %v", err)
+		}
+		utils.RespondWithJSON(w, http.StatusAccepted, map[string]any{
+			"message": "If the email is registered, a password reset link has been sent",
+		})
+	}
+}
+
+// HandlerConfirmPasswordReset sets a new password with a mailed reset token,
+// every session of the user is logged out
+func HandlerConfirmPasswordReset(resets *services.PasswordResetService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Ensure the request method is POST
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Token    string `json:"token"`
+			Password string `json:"password"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Token == "" || req.Password == "" {
+			http.Error(w, "Missing required fields", http.StatusBadRequest)
+			return
+		}
+		if len(req.Password) < 6 {
+			http.Error(w, "Password must be at least 6 characters long", http.StatusBadRequest)
+			return
+		}
+		if err := resets.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+			if err == services.ErrInvalidResetToken {
+				http.Error(w, "Invalid or expired reset token", http.StatusBadRequest)
+				return
+			}
+			log.Printf("Error resetting password: %v", err)
+			http.Error(w, "Failed to reset password", http.StatusInternalServerError)
+			return
+		}
+		clearAuthCookies(w)
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message": "Password reset successfully",
+		})
+	}
+}
@@ -6,19 +6,26 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
 	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
 	"github.com/go-chi/chi"
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func HandlerCreatePost(coll *mongo.Collection) http.HandlerFunc {
+func HandlerCreatePost(posts storage.PostStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Ensure the request method is POST
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
 		// get the fields from the client
 		var req struct {
 			Title       string `json:"title"`
@@ -34,13 +41,20 @@ func HandlerCreatePost(coll *mongo.Collection) http.HandlerFunc {
 			http.Error(w, "Missing required fields", http.StatusBadRequest)
 			return
 		}
-		coll.InsertOne(r.Context(), map[string]any{
-			"title":       req.Title,
-			"description": req.Description,
-			"link":        req.Link,
-			"created_at":  time.Now(),
-			"updated_at":  time.Now(),
-		})
+		post := models.Post{
+			Title:       req.Title,
+			Description: req.Description,
+			Link:        req.Link,
+			UserID:      user.UserID,
+			PublishedAt: time.Now(),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		if err := posts.CreatePost(r.Context(), &post); err != nil {
+			log.Printf("Error creating post: %v", err)
+			http.Error(w, "Failed to create post", http.StatusInternalServerError)
+			return
+		}
 		// Respond with a success message
 		utils.RespondWithJSON(w, http.StatusCreated, map[string]any{
 			"message": "Post created successfully",
@@ -48,39 +62,60 @@ func HandlerCreatePost(coll *mongo.Collection) http.HandlerFunc {
 	}
 }
 
-func HandlerGetPosts(coll *mongo.Collection) http.HandlerFunc {
+func HandlerGetPosts(posts storage.PostStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		//force get methode
 		if r.Method != http.MethodGet {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
-		//get all the posts from the database
-		cursor, err := coll.Find(r.Context(), map[string]any{})
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		query, err := parsePostListQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		//get the posts of the followed feeds and the user's own posts,
+		//one extra post tells if there is a next page
+		limit := query.Limit
+		query.Limit++
+		page, err := posts.ListPosts(r.Context(), user.UserID, query)
 		if err != nil {
+			log.Printf("Error fetching posts: %v", err)
 			http.Error(w, "Failed to fetch posts", http.StatusInternalServerError)
 			return
 		}
-		var posts []map[string]any
-		if err = cursor.All(r.Context(), &posts); err != nil {
-			http.Error(w, "Failed to decode posts", http.StatusInternalServerError)
+		page, nextCursor := nextPage(page, limit)
+		views, err := withPostStates(r, posts, user.UserID, page)
+		if err != nil {
+			http.Error(w, "Failed to fetch post states", http.StatusInternalServerError)
 			return
 		}
 
 		// Respond with the posts
 		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
-			"posts": posts,
+			"posts":       views,
+			"next_cursor": nextCursor,
 		})
 	}
 }
 
-func HandlerGetPostByID(coll *mongo.Collection) http.HandlerFunc {
+func HandlerGetPostByID(posts storage.PostStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		//force get methode
 		if r.Method != http.MethodGet {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
 		//get the id from the url
 		id := chi.URLParam(r, "id")
 		if id == "" {
@@ -88,15 +123,14 @@ func HandlerGetPostByID(coll *mongo.Collection) http.HandlerFunc {
 			return
 		}
 		//get the post from the database
-		var post map[string]any
 		objectID, err := bson.ObjectIDFromHex(id)
 		if err != nil {
 			http.Error(w, "Invalid post ID", http.StatusBadRequest)
 			return
 		}
-		err = coll.FindOne(r.Context(), map[string]any{"_id": objectID}).Decode(&post)
+		post, err := posts.GetVisiblePost(r.Context(), user.UserID, objectID)
 		if err != nil {
-			if err == mongo.ErrNoDocuments {
+			if err == storage.ErrNotFound {
 				http.Error(w, "Post not found", http.StatusNotFound)
 			} else {
 				log.Printf("Error fetching post: %v", err)
@@ -108,20 +142,25 @@ func HandlerGetPostByID(coll *mongo.Collection) http.HandlerFunc {
 	}
 }
 
-func HandlerUpdatePost(coll *mongo.Collection) http.HandlerFunc {
+func HandlerUpdatePost(posts storage.PostStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		//force put methode
 		if r.Method != http.MethodPut {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
 		//get the id from the url
 		id := chi.URLParam(r, "id")
 		if id == "" {
 			http.Error(w, "Missing post ID", http.StatusBadRequest)
 			return
 		}
-	    ObjectID, err := bson.ObjectIDFromHex(id)
+		ObjectID, err := bson.ObjectIDFromHex(id)
 		if err != nil {
 			http.Error(w, "Invalid post ID", http.StatusBadRequest)
 			return
@@ -142,24 +181,25 @@ func HandlerUpdatePost(coll *mongo.Collection) http.HandlerFunc {
 			return
 		}
 		// Update the post in the database
-		update := bson.M{}
+		update := storage.PostUpdate{}
 		if req.Title != "" {
-			update["title"] = req.Title
+			update.Title = &req.Title
 		}
 		if req.Description != "" {
-			update["description"] = req.Description
+			update.Description = &req.Description
 		}
 		if req.Link != "" {
-			update["link"] = req.Link
+			update.Link = &req.Link
 		}
-		update["updated_at"] = time.Now()
-		result, err := coll.UpdateOne(r.Context(), map[string]any{"_id": ObjectID}, bson.M{"$set": update})
-		if err != nil {
-			http.Error(w, "Failed to update post", http.StatusInternalServerError)
+		if !checkPostOwner(w, r, posts, ObjectID, user) {
 			return
 		}
-		if result.MatchedCount == 0 {
-			http.Error(w, "Post not found", http.StatusNotFound)
+		if err := posts.UpdatePost(r.Context(), ObjectID, update); err != nil {
+			if err == storage.ErrNotFound {
+				http.Error(w, "Post not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to update post", http.StatusInternalServerError)
 			return
 		}
 		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
@@ -168,15 +208,20 @@ func HandlerUpdatePost(coll *mongo.Collection) http.HandlerFunc {
 	}
 }
 
-func HandlerDeletePost(coll *mongo.Collection) http.HandlerFunc {
+func HandlerDeletePost(posts storage.PostStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		//force delete methode	
+		//force delete methode
 		if r.Method != http.MethodDelete {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
 		//get the id from the url
-		id := chi.URLParam(r, "id")	
+		id := chi.URLParam(r, "id")
 		if id == "" {
 			http.Error(w, "Missing post ID", http.StatusBadRequest)
 			return
@@ -186,14 +231,16 @@ func HandlerDeletePost(coll *mongo.Collection) http.HandlerFunc {
 			http.Error(w, "Invalid post ID", http.StatusBadRequest)
 			return
 		}
-		//delete the post from the database
-		result, err := coll.DeleteOne(r.Context(), map[string]any{"_id": objectID})
-		if err != nil {
-			http.Error(w, "Failed to delete post", http.StatusInternalServerError)
+		if !checkPostOwner(w, r, posts, objectID, user) {
 			return
 		}
-		if result.DeletedCount == 0 {
-			http.Error(w, "Post not found", http.StatusNotFound)
+		//delete the post from the database
+		if err := posts.DeletePost(r.Context(), objectID); err != nil {
+			if err == storage.ErrNotFound {
+				http.Error(w, "Post not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to delete post", http.StatusInternalServerError)
 			return
 		}
 		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
@@ -201,3 +248,30 @@ func HandlerDeletePost(coll *mongo.Collection) http.HandlerFunc {
 		})
 	}
 }
+
+// checkPostOwner writes the error response and returns false when the post
+// does not exist or was not created by the user, admins may change any post.
+// A post the user cannot see is not found, so its id is not given away.
+func checkPostOwner(w http.ResponseWriter, r *http.Request, posts storage.PostStore, id bson.ObjectID, user *models.AccessTokenClaims) bool {
+	var post *models.Post
+	var err error
+	if user.HasRole(models.RoleAdmin) {
+		post, err = posts.GetPost(r.Context(), id)
+	} else {
+		post, err = posts.GetVisiblePost(r.Context(), user.UserID, id)
+	}
+	if err != nil {
+		if err == storage.ErrNotFound {
+			http.Error(w, "Post not found", http.StatusNotFound)
+		} else {
+			log.Printf("Error fetching post: %v", err)
+			http.Error(w, "Failed to fetch post", http.StatusInternalServerError)
+		}
+		return false
+	}
+	if post.UserID != user.UserID && !user.HasRole(models.RoleAdmin) {
+		http.Error(w, "Forbidden: you are not the owner of this post", http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	defaultPostsLimit = 20
+	maxPostsLimit     = 100
+)
+
+// parsePostFilters reads the filters shared by the posts list and search endpoints
+func parsePostFilters(r *http.Request) (storage.PostFilter, error) {
+	query := r.URL.Query()
+	filters := storage.PostFilter{
+		Category: query.Get("category"),
+		Author:   query.Get("author"),
+	}
+	if feedID := query.Get("feed_id"); feedID != "" {
+		id, err := bson.ObjectIDFromHex(feedID)
+		if err != nil {
+			return filters, errors.New("invalid feed_id")
+		}
+		filters.FeedID = &id
+	}
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{{"since", &filters.Since}, {"until", &filters.Until}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filters, errors.New("invalid " + param.name + ", expected an RFC 3339 date")
+		}
+		*param.target = &t
+	}
+	return filters, nil
+}
+
+// parsePostListQuery reads a page request of GET /v1/posts, the cursor is the
+// opaque base64 string returned with the previous page
+func parsePostListQuery(r *http.Request) (storage.PostListQuery, error) {
+	filters, err := parsePostFilters(r)
+	if err != nil {
+		return storage.PostListQuery{}, err
+	}
+	query := r.URL.Query()
+	q := storage.PostListQuery{PostFilter: filters, Limit: defaultPostsLimit}
+	if limit := query.Get("limit"); limit != "" {
+		q.Limit, err = strconv.Atoi(limit)
+		if err != nil || q.Limit <= 0 {
+			return storage.PostListQuery{}, errors.New("invalid limit")
+		}
+		if q.Limit > maxPostsLimit {
+			q.Limit = maxPostsLimit
+		}
+	}
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		q.Ascending = true
+	default:
+		return storage.PostListQuery{}, errors.New("invalid order, expected asc or desc")
+	}
+	if q.UnreadOnly, err = parseBoolParam(query.Get("unread_only")); err != nil {
+		return storage.PostListQuery{}, errors.New("invalid unread_only")
+	}
+	if q.StarredOnly, err = parseBoolParam(query.Get("starred_only")); err != nil {
+		return storage.PostListQuery{}, errors.New("invalid starred_only")
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		q.After, err = decodePostCursor(cursor)
+		if err != nil {
+			return storage.PostListQuery{}, errors.New("invalid cursor")
+		}
+	}
+	return q, nil
+}
+
+func parseBoolParam(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// nextPage trims the extra post fetched to know if there is a next page and
+// returns the cursor of that page
+func nextPage(posts []models.Post, limit int) ([]models.Post, string) {
+	if len(posts) <= limit {
+		return posts, ""
+	}
+	posts = posts[:limit]
+	last := posts[len(posts)-1]
+	return posts, encodePostCursor(storage.PostCursor{PublishedAt: last.PublishedAt, ID: last.ID})
+}
+
+func encodePostCursor(cursor storage.PostCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePostCursor(value string) (*storage.PostCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor storage.PostCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+	"github.com/go-chi/chi"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// maxBatchSize caps the number of ids accepted by the batch endpoints
+const maxBatchSize = 500
+
+// postView is a post with the read and starred state of the current user
+type postView struct {
+	models.Post
+	Read    bool `json:"read"`
+	Starred bool `json:"starred"`
+}
+
+// HandlerSetPostRead marks a post as read (POST) or unread (DELETE)
+func HandlerSetPostRead(posts storage.PostStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, post, ok := findVisiblePost(w, r, posts)
+		if !ok {
+			return
+		}
+		read := r.Method == http.MethodPost
+		if err := posts.SetPostsRead(r.Context(), user.UserID, []models.Post{*post}, read); err != nil {
+			log.Printf("Error updating post state: %v", err)
+			http.Error(w, "Failed to update post state", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"post_id": post.ID,
+			"read":    read,
+		})
+	}
+}
+
+// HandlerSetPostStarred stars (POST) or unstars (DELETE) a post
+func HandlerSetPostStarred(posts storage.PostStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, post, ok := findVisiblePost(w, r, posts)
+		if !ok {
+			return
+		}
+		starred := r.Method == http.MethodPost
+		if err := posts.SetPostStarred(r.Context(), user.UserID, *post, starred); err != nil {
+			log.Printf("Error updating post state: %v", err)
+			http.Error(w, "Failed to update post state", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"post_id": post.ID,
+			"starred": starred,
+		})
+	}
+}
+
+// HandlerMarkPostsRead marks a batch of posts as read, or unread with "read": false
+func HandlerMarkPostsRead(posts storage.PostStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Ensure the request method is POST
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		var req struct {
+			IDs  []string `json:"ids"`
+			Read *bool    `json:"read"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if len(req.IDs) == 0 || len(req.IDs) > maxBatchSize {
+			http.Error(w, "Between 1 and 500 post IDs are required", http.StatusBadRequest)
+			return
+		}
+		ids := make([]bson.ObjectID, 0, len(req.IDs))
+		for _, id := range req.IDs {
+			objectID, err := bson.ObjectIDFromHex(id)
+			if err != nil {
+				http.Error(w, "Invalid post ID: "+id, http.StatusBadRequest)
+				return
+			}
+			ids = append(ids, objectID)
+		}
+		read := req.Read == nil || *req.Read
+
+		// only the posts the user can see are marked
+		visible, err := posts.GetVisiblePosts(r.Context(), user.UserID, ids)
+		if err != nil {
+			http.Error(w, "Failed to fetch posts", http.StatusInternalServerError)
+			return
+		}
+		if err := posts.SetPostsRead(r.Context(), user.UserID, visible, read); err != nil {
+			log.Printf("Error updating post states: %v", err)
+			http.Error(w, "Failed to update post states", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"updated": len(visible),
+			"read":    read,
+		})
+	}
+}
+
+// HandlerMarkFeedRead marks the posts of a followed feed published before a date as read
+func HandlerMarkFeedRead(posts storage.PostStore, feeds storage.FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Ensure the request method is POST
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		feedID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+			return
+		}
+		// without a date every post of the feed is marked
+		var req struct {
+			Before *time.Time `json:"before"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		following, err := feeds.IsFollowing(r.Context(), user.UserID, feedID)
+		if err != nil {
+			http.Error(w, "Failed to fetch feed follows", http.StatusInternalServerError)
+			return
+		}
+		if !following {
+			http.Error(w, "Feed not followed", http.StatusNotFound)
+			return
+		}
+		updated, err := posts.MarkFeedRead(r.Context(), user.UserID, feedID, req.Before)
+		if err != nil {
+			log.Printf("Error updating post states: %v", err)
+			http.Error(w, "Failed to update post states", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"updated": updated,
+		})
+	}
+}
+
+func HandlerGetUnreadCounts(posts storage.PostStore, feeds storage.FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		//force get methode
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		feedIDs, err := feeds.FollowedFeedIDs(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Failed to fetch feed follows", http.StatusInternalServerError)
+			return
+		}
+		counts, err := posts.CountUnreadPosts(r.Context(), user.UserID, feedIDs)
+		if err != nil {
+			log.Printf("Error counting unread posts: %v", err)
+			http.Error(w, "Failed to count unread posts", http.StatusInternalServerError)
+			return
+		}
+		type feedCount struct {
+			FeedID bson.ObjectID `json:"feed_id"`
+			Unread int64         `json:"unread"`
+		}
+		feedCounts := make([]feedCount, 0, len(feedIDs))
+		var total int64
+		for _, feedID := range feedIDs {
+			feedCounts = append(feedCounts, feedCount{FeedID: feedID, Unread: counts[feedID]})
+			total += counts[feedID]
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"feeds": feedCounts,
+			"total": total,
+		})
+	}
+}
+
+// findVisiblePost loads the post of the {id} url parameter, it writes the error
+// response and returns false when the user cannot see it
+func findVisiblePost(w http.ResponseWriter, r *http.Request, posts storage.PostStore) (*models.AccessTokenClaims, *models.Post, bool) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return nil, nil, false
+	}
+	objectID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	post, err := posts.GetVisiblePost(r.Context(), user.UserID, objectID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			http.Error(w, "Post not found", http.StatusNotFound)
+		} else {
+			log.Printf("Error fetching post: %v", err)
+			http.Error(w, "Failed to fetch post", http.StatusInternalServerError)
+		}
+		return nil, nil, false
+	}
+	return user, post, true
+}
+
+// withPostStates adds the read and starred state of the user to the posts
+func withPostStates(r *http.Request, store storage.PostStore, userID bson.ObjectID, posts []models.Post) ([]postView, error) {
+	ids := make([]bson.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	states, err := store.GetPostStates(r.Context(), userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]postView, 0, len(posts))
+	for _, post := range posts {
+		state := states[post.ID]
+		views = append(views, postView{Post: post, Read: state.Read, Starred: state.Starred})
+	}
+	return views, nil
+}
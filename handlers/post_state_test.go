@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/memory"
+	"github.com/go-chi/chi"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMarkFeedRead(t *testing.T) {
+	store := memory.New()
+	user := testUser()
+	feed := createFeed(t, store, user.UserID)
+	other := createFeed(t, store, user.UserID)
+	for _, id := range []bson.ObjectID{feed.ID, other.ID} {
+		if _, err := store.FollowFeed(context.Background(), user.UserID, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	published := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []bson.ObjectID
+	for i := range 3 {
+		post := createPost(t, store, models.Post{FeedID: feed.ID, PublishedAt: published.Add(time.Duration(i) * time.Hour)})
+		ids = append(ids, post.ID)
+	}
+	ids = append(ids, createPost(t, store, models.Post{FeedID: other.ID, PublishedAt: published}).ID)
+
+	markRead := func(body io.Reader) int64 {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, "/feeds/"+feed.ID.Hex()+"/read", body)
+		r = r.WithContext(context.WithValue(r.Context(), middleware.UserContextKey, user))
+		// a chunked request does not know the length of its body
+		r.ContentLength = -1
+		router := chi.NewRouter()
+		router.Post("/feeds/{id}/read", HandlerMarkFeedRead(store, store))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("mark read: status %d: %s", w.Code, w.Body)
+		}
+		return decode[struct {
+			Updated int64 `json:"updated"`
+		}](t, w).Updated
+	}
+	readPosts := func() []bool {
+		states, err := store.GetPostStates(context.Background(), user.UserID, ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		read := make([]bool, len(ids))
+		for i, id := range ids {
+			read[i] = states[id].Read
+		}
+		return read
+	}
+
+	// the posts published at the date are marked too
+	before := published.Add(time.Hour).Format(time.RFC3339)
+	if updated := markRead(strings.NewReader(`{"before": "` + before + `"}`)); updated != 2 {
+		t.Errorf("updated = %d, want 2", updated)
+	}
+	if got := readPosts(); !got[0] || !got[1] || got[2] || got[3] {
+		t.Errorf("read = %v, want the first two posts", got)
+	}
+	if updated := markRead(http.NoBody); updated != 3 {
+		t.Errorf("updated = %d without body, want 3", updated)
+	}
+	if got := readPosts(); !got[2] || got[3] {
+		t.Errorf("read = %v, want every post of the feed and none of the other", got)
+	}
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/memory"
+	"github.com/go-chi/chi"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// testUser returns the claims of a new user, as the auth middleware would
+// put them in the request context
+func testUser(roles ...string) *models.AccessTokenClaims {
+	return &models.AccessTokenClaims{
+		UserID: bson.NewObjectID(),
+		Roles:  append([]string{models.RoleUser}, roles...),
+	}
+}
+
+// serve runs the handler mounted on the pattern, so URL parameters are read
+// like in the real router, as the given user. A string body is sent as is,
+// other bodies as JSON.
+func serve(t *testing.T, method, pattern string, handler http.HandlerFunc, target string, user *models.AccessTokenClaims, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader bytes.Buffer
+	switch body := body.(type) {
+	case nil:
+	case string:
+		reader.WriteString(body)
+	default:
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := httptest.NewRequest(method, target, &reader)
+	r = r.WithContext(context.WithValue(r.Context(), middleware.UserContextKey, user))
+	router := chi.NewRouter()
+	router.Method(method, pattern, handler)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.NewDecoder(w.Body).Decode(&v); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+	return v
+}
+
+type postsPage struct {
+	Posts      []postView `json:"posts"`
+	NextCursor string     `json:"next_cursor"`
+}
+
+func getPosts(t *testing.T, store *memory.Store, user *models.AccessTokenClaims, query string) postsPage {
+	t.Helper()
+	w := serve(t, http.MethodGet, "/posts", HandlerGetPosts(store), "/posts"+query, user, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /posts%s: status %d: %s", query, w.Code, w.Body)
+	}
+	return decode[postsPage](t, w)
+}
+
+func postIDs(posts []postView) []bson.ObjectID {
+	ids := make([]bson.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	return ids
+}
+
+func createPost(t *testing.T, store *memory.Store, post models.Post) models.Post {
+	t.Helper()
+	if post.PublishedAt.IsZero() {
+		post.PublishedAt = time.Now()
+	}
+	if err := store.CreatePost(context.Background(), &post); err != nil {
+		t.Fatal(err)
+	}
+	return post
+}
+
+func createFeed(t *testing.T, store *memory.Store, owner bson.ObjectID) models.Feed {
+	t.Helper()
+	feed := models.Feed{URL: "https://example.com/" + bson.NewObjectID().Hex(), UserID: owner}
+	if err := store.CreateFeed(context.Background(), &feed); err != nil {
+		t.Fatal(err)
+	}
+	return feed
+}
+
+func TestGetPostsVisibility(t *testing.T) {
+	store := memory.New()
+	alice, bob := testUser(), testUser()
+	followed := createFeed(t, store, bob.UserID)
+	other := createFeed(t, store, bob.UserID)
+	if _, err := store.FollowFeed(context.Background(), alice.UserID, followed.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	own := createPost(t, store, models.Post{Title: "own", UserID: alice.UserID})
+	fromFollowed := createPost(t, store, models.Post{Title: "followed", FeedID: followed.ID})
+	createPost(t, store, models.Post{Title: "not followed", FeedID: other.ID})
+	createPost(t, store, models.Post{Title: "of bob", UserID: bob.UserID})
+
+	page := getPosts(t, store, alice, "?order=asc")
+	got := postIDs(page.Posts)
+	if len(got) != 2 || got[0] != own.ID || got[1] != fromFollowed.ID {
+		t.Fatalf("alice sees %v, want the post of alice %s and the followed one %s", got, own.ID, fromFollowed.ID)
+	}
+	if page.NextCursor != "" {
+		t.Errorf("next_cursor = %q on the last page", page.NextCursor)
+	}
+	if page := getPosts(t, store, bob, ""); len(page.Posts) != 1 || page.Posts[0].Title != "of bob" {
+		t.Errorf("bob sees %v, want only the post of bob", page.Posts)
+	}
+}
+
+func TestGetPostsPagination(t *testing.T) {
+	store := memory.New()
+	user := testUser()
+	published := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := map[bson.ObjectID]bool{}
+	for i := range 7 {
+		// posts published at the same time are ordered by ID
+		post := createPost(t, store, models.Post{
+			Title:       "post",
+			UserID:      user.UserID,
+			PublishedAt: published.Add(time.Duration(i/2) * time.Hour),
+		})
+		want[post.ID] = true
+	}
+
+	for _, order := range []string{"asc", "desc"} {
+		t.Run(order, func(t *testing.T) {
+			seen := map[bson.ObjectID]bool{}
+			var last *postView
+			cursor, pages := "", 0
+			for {
+				page := getPosts(t, store, user, "?limit=3&order="+order+"&cursor="+cursor)
+				pages++
+				for _, post := range page.Posts {
+					if seen[post.ID] {
+						t.Fatalf("post %s returned twice", post.ID)
+					}
+					seen[post.ID] = true
+					if last != nil {
+						cmp := post.PublishedAt.Compare(last.PublishedAt)
+						if cmp == 0 {
+							cmp = bytes.Compare(post.ID[:], last.ID[:])
+						}
+						if (order == "asc" && cmp <= 0) || (order == "desc" && cmp >= 0) {
+							t.Fatalf("post %s is out of order after %s", post.ID, last.ID)
+						}
+					}
+					last = &post
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				cursor = page.NextCursor
+			}
+			if pages != 3 || len(seen) != len(want) {
+				t.Errorf("got %d posts in %d pages, want %d posts in 3 pages", len(seen), pages, len(want))
+			}
+		})
+	}
+
+	w := serve(t, http.MethodGet, "/posts", HandlerGetPosts(store), "/posts?cursor=nope", user, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid cursor: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestUpdatePostOwnership(t *testing.T) {
+	store := memory.New()
+	owner, other, admin := testUser(), testUser(), testUser(models.RoleAdmin)
+	post := createPost(t, store, models.Post{Title: "title", Description: "text", UserID: owner.UserID})
+	feed := createFeed(t, store, owner.UserID)
+	feedPost := createPost(t, store, models.Post{Title: "title", FeedID: feed.ID})
+	if _, err := store.FollowFeed(context.Background(), other.UserID, feed.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	update := func(user *models.AccessTokenClaims, id, title string) int {
+		w := serve(t, http.MethodPut, "/posts/{id}", HandlerUpdatePost(store), "/posts/"+id, user, map[string]string{"title": title})
+		return w.Code
+	}
+	// a post the user cannot see does not exist for them
+	if code := update(other, post.ID.Hex(), "stolen"); code != http.StatusNotFound {
+		t.Errorf("update of a post of another user: status %d, want %d", code, http.StatusNotFound)
+	}
+	if code := update(other, feedPost.ID.Hex(), "stolen"); code != http.StatusForbidden {
+		t.Errorf("update of a post of a followed feed: status %d, want %d", code, http.StatusForbidden)
+	}
+	if code := update(owner, bson.NewObjectID().Hex(), "missing"); code != http.StatusNotFound {
+		t.Errorf("update of a missing post: status %d, want %d", code, http.StatusNotFound)
+	}
+	if code := update(owner, "nope", "invalid"); code != http.StatusBadRequest {
+		t.Errorf("update with an invalid ID: status %d, want %d", code, http.StatusBadRequest)
+	}
+	if got, _ := store.GetPost(context.Background(), post.ID); got.Title != "title" {
+		t.Fatalf("title = %q after refused updates", got.Title)
+	}
+
+	if code := update(owner, post.ID.Hex(), "by owner"); code != http.StatusOK {
+		t.Errorf("update by the owner: status %d, want %d", code, http.StatusOK)
+	}
+	if code := update(admin, post.ID.Hex(), "by admin"); code != http.StatusOK {
+		t.Errorf("update by an admin: status %d, want %d", code, http.StatusOK)
+	}
+	got, _ := store.GetPost(context.Background(), post.ID)
+	if got.Title != "by admin" || got.Description != "text" {
+		t.Errorf("post = %q %q, want the admin title and the description kept", got.Title, got.Description)
+	}
+}
+
+func TestDeletePostOwnership(t *testing.T) {
+	store := memory.New()
+	owner, other, admin := testUser(), testUser(), testUser(models.RoleAdmin)
+	post := createPost(t, store, models.Post{Title: "title", UserID: owner.UserID})
+	adminTarget := createPost(t, store, models.Post{Title: "title", UserID: owner.UserID})
+
+	remove := func(user *models.AccessTokenClaims, id bson.ObjectID) int {
+		return serve(t, http.MethodDelete, "/posts/{id}", HandlerDeletePost(store), "/posts/"+id.Hex(), user, nil).Code
+	}
+	if code := remove(other, post.ID); code != http.StatusNotFound {
+		t.Errorf("delete by another user: status %d, want %d", code, http.StatusNotFound)
+	}
+	if _, err := store.GetPost(context.Background(), post.ID); err != nil {
+		t.Fatalf("post is gone after a refused delete: %v", err)
+	}
+	if code := remove(owner, post.ID); code != http.StatusOK {
+		t.Errorf("delete by the owner: status %d, want %d", code, http.StatusOK)
+	}
+	if code := remove(owner, post.ID); code != http.StatusNotFound {
+		t.Errorf("second delete: status %d, want %d", code, http.StatusNotFound)
+	}
+	if code := remove(admin, adminTarget.ID); code != http.StatusOK {
+		t.Errorf("delete by an admin: status %d, want %d", code, http.StatusOK)
+	}
+	if page := getPosts(t, store, owner, ""); len(page.Posts) != 0 {
+		t.Errorf("owner still sees %d posts", len(page.Posts))
+	}
+}
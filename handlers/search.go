@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"html"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+)
+
+// snippetLength is the number of characters around the first match shown in a snippet
+const snippetLength = 200
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// searchResult is a post matching a search with its relevance and highlights,
+// highlights are HTML escaped with the matches wrapped in <mark>
+type searchResult struct {
+	storage.ScoredPost
+	Highlights struct {
+		Title   string `json:"title"`
+		Snippet string `json:"snippet"`
+	} `json:"highlights"`
+}
+
+func HandlerSearchPosts(posts storage.PostStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		//force get methode
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		text := strings.TrimSpace(r.URL.Query().Get("q"))
+		if text == "" {
+			http.Error(w, "Query parameter q is required", http.StatusBadRequest)
+			return
+		}
+		filters, err := parsePostFilters(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit, offset := defaultPostsLimit, 0
+		if value := r.URL.Query().Get("limit"); value != "" {
+			if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = min(limit, maxPostsLimit)
+		}
+		if value := r.URL.Query().Get("offset"); value != "" {
+			if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+				http.Error(w, "invalid offset", http.StatusBadRequest)
+				return
+			}
+		}
+
+		found, err := posts.SearchPosts(r.Context(), user.UserID, storage.PostSearchQuery{
+			PostFilter: filters,
+			Text:       text,
+			Limit:      limit,
+			Offset:     offset,
+		})
+		if err != nil {
+			log.Printf("Error searching posts: %v", err)
+			http.Error(w, "Failed to search posts", http.StatusInternalServerError)
+			return
+		}
+		terms := searchTerms(text)
+		results := make([]searchResult, len(found))
+		for i, post := range found {
+			results[i].ScoredPost = post
+			results[i].Highlights.Title = highlight(post.Title, terms)
+			results[i].Highlights.Snippet = snippet(firstText(post.Content, post.Description), terms)
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"query":   text,
+			"results": results,
+		})
+	}
+}
+
+// searchTerms extracts the words and phrases of a $text query, negated terms are dropped
+func searchTerms(query string) []string {
+	var terms []string
+	for i, part := range strings.Split(query, `"`) {
+		part = strings.TrimSpace(part)
+		if i%2 == 1 {
+			if part != "" {
+				terms = append(terms, part)
+			}
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			if !strings.HasPrefix(word, "-") {
+				terms = append(terms, word)
+			}
+		}
+	}
+	return terms
+}
+
+func firstText(values ...string) string {
+	for _, value := range values {
+		text := strings.Join(strings.Fields(html.UnescapeString(htmlTagPattern.ReplaceAllString(value, " "))), " ")
+		if text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// snippet cuts the text around the first matching term and highlights it
+func snippet(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	start := 0
+	for _, term := range terms {
+		if i := strings.Index(lower, strings.ToLower(term)); i >= 0 {
+			start = max(i-snippetLength/4, 0)
+			break
+		}
+	}
+	// keep the cut on rune boundaries
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	end := min(start+snippetLength, len(text))
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	result := highlight(text[start:end], terms)
+	if start > 0 {
+		result = "…" + result
+	}
+	if end < len(text) {
+		result += "…"
+	}
+	return result
+}
+
+// highlight HTML escapes the text and wraps the terms in <mark>
+func highlight(text string, terms []string) string {
+	var quoted []string
+	for _, term := range terms {
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	if len(quoted) == 0 {
+		return html.EscapeString(text)
+	}
+	pattern := regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+	var b strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:match[0]]))
+		b.WriteString("<mark>" + html.EscapeString(text[match[0]:match[1]]) + "</mark>")
+		last = match[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
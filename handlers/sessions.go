@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/services"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+	"github.com/go-chi/chi"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type sessionResponse struct {
+	ID         bson.ObjectID `json:"id"`
+	UserAgent  string        `json:"user_agent"`
+	IP         string        `json:"ip"`
+	CreatedAt  time.Time     `json:"created_at"`
+	LastUsedAt time.Time     `json:"last_used_at"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	Scopes     []string      `json:"scopes"`
+	Current    bool          `json:"current"`
+}
+
+// HandlerGetSessions lists the active sessions of the user, the session of
+// the request is marked as current
+func HandlerGetSessions(tokenService *services.TokenService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		sessions, err := tokenService.ListSessions(r.Context(), user.UserID)
+		if err != nil {
+			log.Printf("Error fetching sessions: %v", err)
+			http.Error(w, "Failed to fetch sessions", http.StatusInternalServerError)
+			return
+		}
+		response := make([]sessionResponse, 0, len(sessions))
+		for _, session := range sessions {
+			response = append(response, sessionResponse{
+				ID:         session.ID,
+				UserAgent:  session.UserAgent,
+				IP:         session.IP,
+				CreatedAt:  session.CreatedAt,
+				LastUsedAt: session.LastUsedAt,
+				ExpiresAt:  session.ExpiresAt,
+				Scopes:     session.Scopes,
+				Current:    session.ID == user.SessionID,
+			})
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"sessions": response,
+		})
+	}
+}
+
+// HandlerDeleteSession revokes one session of the user, revoking the current
+// session logs the client out
+func HandlerDeleteSession(tokenService *services.TokenService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		sessionID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid session ID", http.StatusBadRequest)
+			return
+		}
+		if err := tokenService.RevokeSession(r.Context(), user.UserID, sessionID); err != nil {
+			if err == storage.ErrNotFound {
+				http.Error(w, "Session not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("Error revoking session %s: %v", sessionID.Hex(), err)
+			http.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+			return
+		}
+		if sessionID == user.SessionID {
+			clearAuthCookies(w)
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message": "Session revoked successfully",
+		})
+	}
+}
+
+// HandlerDeleteOtherSessions logs the user out everywhere but in the session
+// of the request
+func HandlerDeleteOtherSessions(tokenService *services.TokenService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		revoked, err := tokenService.RevokeOtherSessions(r.Context(), user.UserID, user.SessionID)
+		if err != nil {
+			log.Printf("Error revoking sessions: %v", err)
+			http.Error(w, "Failed to revoke sessions", http.StatusInternalServerError)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, map[string]any{
+			"message": "Other sessions revoked successfully",
+			"revoked": revoked,
+		})
+	}
+}
+
+// revokeRefreshSession revokes the session a validated refresh token belongs
+// to, a session that is already gone is not an error
+func revokeRefreshSession(r *http.Request, tokenService *services.TokenService, claims *models.RefreshTokenClaims) error {
+	sessionID, err := bson.ObjectIDFromHex(claims.FamilyID)
+	if err != nil {
+		return nil
+	}
+	err = tokenService.RevokeSession(r.Context(), claims.UserID, sessionID)
+	if err == storage.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// clientIP returns the address of the peer of the request, without its port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/config"
+	"github.com/Aym-Aymen777/RSS-Aggregator/mailer"
+)
+
+// openMailer returns the mailer selected by MAIL_DRIVER
+func openMailer(cfg *config.MailConfig) (mailer.Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	case "log":
+		return mailer.NewLogMailer(cfg.From), nil
+	case "file":
+		return mailer.NewFileMailer(cfg.FilePath, cfg.From), nil
+	}
+	return nil, fmt.Errorf("unknown MAIL_DRIVER %q, expected smtp, log or file", cfg.Driver)
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+)
+
+// LogMailer writes the emails to the application log instead of sending them
+type LogMailer struct {
+	from string
+}
+
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("📧 Mail from %s to %s: %s\n%s", m.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileMailer appends the emails to a file instead of sending them
+type FileMailer struct {
+	mu   sync.Mutex
+	path string
+	from string
+}
+
+func NewFileMailer(path, from string) *FileMailer {
+	return &FileMailer{path: path, from: from}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(format(m.from, msg), "\r\n"...)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
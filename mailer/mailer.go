@@ -0,0 +1,35 @@
+// Package mailer sends the emails of the application, like password reset
+// links, through SMTP or to a log for local development.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// format renders the message with its headers, lines end with CRLF as SMTP expects
+func format(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
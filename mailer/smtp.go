@@ -0,0 +1,68 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTPMailer sends emails through an SMTP server, the connection is upgraded
+// with STARTTLS when the server supports it
+type SMTPMailer struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{host: host, port: port, username: username, password: password, from: from}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.host, strconv.Itoa(m.port)))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(format(m.from, msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
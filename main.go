@@ -12,6 +12,9 @@ import (
 	"github.com/Aym-Aymen777/RSS-Aggregator/config"
 	"github.com/Aym-Aymen777/RSS-Aggregator/handlers"
 	"github.com/Aym-Aymen777/RSS-Aggregator/middleware"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/oidc"
+	"github.com/Aym-Aymen777/RSS-Aggregator/scraper"
 	"github.com/Aym-Aymen777/RSS-Aggregator/services"
 	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
 	"github.com/go-chi/chi"
@@ -27,41 +30,83 @@ func main() {
 		log.Fatal("PORT environment variable is not set")
 	}
 
-	// Initialize MongoDB (single shared client)
-	connectDB()
+	// Every handler and the scraper share the same storage backend
+	store, err := openStore(config.NewStorageConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	// the first admin is promoted from the environment
+	if email := config.NewRBACConfig().BootstrapAdminEmail; email != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := services.BootstrapAdmin(ctx, store, email)
+		cancel()
+		if err != nil {
+			log.Fatalf("Failed to bootstrap admin: %v", err)
+		}
+	}
 
 	jwtConfig := config.NewJWTConfig()
-	tokenService := services.NewTokenService(jwtConfig)
+	tokenService := services.NewTokenService(jwtConfig, store, store)
+
+	mail, err := openMailer(config.NewMailConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+	passwordResetService := services.NewPasswordResetService(config.NewPasswordResetConfig(), store, store, mail, tokenService)
+
+	verificationConfig := config.NewEmailVerificationConfig()
+	switch verificationConfig.Policy {
+	case config.VerificationPolicyNone, config.VerificationPolicyLogin, config.VerificationPolicyWrites:
+	default:
+		log.Fatalf("Unknown EMAIL_VERIFICATION_POLICY %q, expected none, login or writes", verificationConfig.Policy)
+	}
+	emailVerificationService := services.NewEmailVerificationService(verificationConfig, store, store, mail)
+	mfaService := services.NewMFAService(config.NewMFAConfig(), store, store)
+	apiKeyService := services.NewAPIKeyService(store, store)
+
+	// single sign-on is enabled with the issuer of the identity provider
+	oidcConfig := config.NewOIDCConfig()
+	var oidcService *services.OIDCService
+	if oidcConfig.IssuerURL != "" {
+		if oidcConfig.ClientID == "" {
+			log.Fatal("OIDC_CLIENT_ID environment variable is not set")
+		}
+		provider := oidc.NewProvider(oidcConfig.IssuerURL, oidcConfig.ClientID, oidcConfig.ClientSecret, oidcConfig.RedirectURL, oidcConfig.Scopes)
+		oidcService = services.NewOIDCService(oidcConfig, provider, store, store, store, store, tokenService)
+	}
 
 	router := chi.NewRouter()
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 
 	v1 := chi.NewRouter()
 	//Health check endpoints
-	v1.Get("/ready", handlerReadiness)
+	v1.Get("/ready", handlerReadiness(store))
 	v1.Get("/error", handlerErr)
 
-	//CRUD operations endpoints for users
-	v1.Post("/users/create", handlerCreateUser)
-	v1.Post("/users/create-many", handlerCreateManyUsers)
-	v1.Get("/users", handlerFindUserByEmail)
-	v1.Put("/users/update", handlerUpdateUser)
-
 	// Public routes (no authentication required)
-	authCollection := MongoClient.Database("rssagg").Collection("auths")
-	v1.Post("/auth/register", handlers.HandlerRagisterUser(authCollection))
-	v1.Post("/auth/login", handlers.HandlerLoginUser(authCollection, tokenService))
-	v1.Post("/auth/refresh", handlers.HandlerRefreshToken(authCollection, tokenService))
+	v1.Post("/auth/register", handlers.HandlerRagisterUser(store, emailVerificationService))
+	v1.Post("/auth/login", handlers.HandlerLoginUser(store, tokenService, emailVerificationService, mfaService))
+	v1.Post("/auth/login/mfa", handlers.HandlerLoginMFA(store, tokenService, mfaService))
+	v1.Post("/auth/refresh", handlers.HandlerRefreshToken(store, tokenService))
+	v1.Post("/auth/logout", handlers.HandlerLogout(tokenService))
+	v1.Post("/auth/password-reset/request", handlers.HandlerRequestPasswordReset(passwordResetService))
+	v1.Post("/auth/password-reset/confirm", handlers.HandlerConfirmPasswordReset(passwordResetService))
+	v1.Post("/auth/verify-email/request", handlers.HandlerRequestEmailVerification(emailVerificationService))
+	v1.Post("/auth/verify-email/confirm", handlers.HandlerConfirmEmailVerification(emailVerificationService))
+	if oidcService != nil {
+		v1.Get("/auth/oidc/login", handlers.HandlerOIDCLogin(oidcService, tokenService))
+		v1.Get("/auth/oidc/callback", handlers.HandlerOIDCCallback(oidcService, tokenService, emailVerificationService, mfaService))
+	}
 	// Protected routes (authentication required)
-	postsCollection := MongoClient.Database("rssagg").Collection("posts")
 	v1.Group(func(r chi.Router) {
-		r.Use(middleware.AuthMidlleware(tokenService))
+		r.Use(middleware.AuthMidlleware(tokenService, apiKeyService))
 		r.Get("/protected", func(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte("This is a protected route"))
 		})
@@ -73,12 +118,72 @@ func main() {
 			}
 			utils.RespondWithJSON(w, http.StatusOK, user)
 		})
-		r.Post("/posts/create", handlers.HandlerCreatePost(postsCollection))
-		r.Get("/posts", handlers.HandlerGetPosts(postsCollection))
-		r.Get("/posts/{id}", handlers.HandlerGetPostByID(postsCollection))
-		r.Put("/posts/{id}", handlers.HandlerUpdatePost(postsCollection))
-		r.Delete("/posts/{id}", handlers.HandlerDeletePost(postsCollection))
-
+		// credentials are only managed from a login, not with an API key
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireSession)
+			r.Get("/auth/sessions", handlers.HandlerGetSessions(tokenService))
+			r.Delete("/auth/sessions", handlers.HandlerDeleteOtherSessions(tokenService))
+			r.Delete("/auth/sessions/{id}", handlers.HandlerDeleteSession(tokenService))
+			r.Post("/auth/2fa/enroll", handlers.HandlerEnrollMFA(mfaService))
+			r.Post("/auth/2fa/confirm", handlers.HandlerConfirmMFA(mfaService))
+			r.Post("/auth/2fa/disable", handlers.HandlerDisableMFA(mfaService))
+			r.Post("/auth/api-keys", handlers.HandlerCreateAPIKey(apiKeyService))
+			r.Get("/auth/api-keys", handlers.HandlerGetAPIKeys(apiKeyService))
+			r.Delete("/auth/api-keys/{id}", handlers.HandlerDeleteAPIKey(apiKeyService))
+		})
+		// user management is for admins only
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(models.RoleAdmin), middleware.RequireScopes(models.ScopeAdmin))
+			//CRUD operations endpoints for users
+			r.Post("/users/create", handlerCreateUser(store))
+			r.Post("/users/create-many", handlerCreateManyUsers(store))
+			r.Get("/users", handlerFindUserByEmail(store))
+			r.Put("/users/update", handlerUpdateUser(store))
+			r.Put("/admin/users/roles", handlers.HandlerSetUserRoles(store))
+		})
+		// the policy may keep unverified users from changing anything
+		r.Group(func(r chi.Router) {
+			if emailVerificationService.BlocksWrites() {
+				r.Use(middleware.RequireVerifiedEmail(store))
+			}
+			// the scopes of the token decide what it can be used for
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireScopes(models.ScopePostsRead))
+				r.Get("/posts", handlers.HandlerGetPosts(store))
+				r.Get("/posts/search", handlers.HandlerSearchPosts(store))
+				r.Get("/posts/feed.json", handlers.HandlerGetPostsJSONFeed(store))
+				r.Get("/posts/{id}", handlers.HandlerGetPostByID(store))
+				r.Get("/feeds/export.opml", handlers.HandlerExportOPML(store))
+				r.Get("/feeds", handlers.HandlerGetFeeds(store))
+				r.Get("/feeds/unread-counts", handlers.HandlerGetUnreadCounts(store, store))
+				r.Get("/feeds/{id}", handlers.HandlerGetFeedByID(store))
+				r.Get("/feed_follows", handlers.HandlerGetFeedFollows(store))
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireScopes(models.ScopePostsWrite))
+				r.Post("/posts/create", handlers.HandlerCreatePost(store))
+				r.Post("/posts/read", handlers.HandlerMarkPostsRead(store))
+				r.Put("/posts/{id}", handlers.HandlerUpdatePost(store))
+				r.Delete("/posts/{id}", handlers.HandlerDeletePost(store))
+				r.Post("/posts/{id}/read", handlers.HandlerSetPostRead(store))
+				r.Delete("/posts/{id}/read", handlers.HandlerSetPostRead(store))
+				r.Post("/posts/{id}/star", handlers.HandlerSetPostStarred(store))
+				r.Delete("/posts/{id}/star", handlers.HandlerSetPostStarred(store))
+				r.Post("/feeds/{id}/read", handlers.HandlerMarkFeedRead(store, store))
+			})
+			// every account has at least the user role, so RequireRole would
+			// let everyone through here: the role check of feed management is
+			// done per feed by findUserFeed, which lets the owner of a feed or
+			// an admin change it and answers 404 to everyone else
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireScopes(models.ScopeFeedsWrite))
+				r.Post("/feeds/create", handlers.HandlerCreateFeed(store))
+				r.Post("/feeds/import", handlers.HandlerImportOPML(store))
+				r.Delete("/feeds/{id}", handlers.HandlerDeleteFeed(store))
+				r.Post("/feed_follows", handlers.HandlerCreateFeedFollow(store))
+				r.Delete("/feed_follows/{id}", handlers.HandlerDeleteFeedFollow(store))
+			})
+		})
 	})
 	router.Mount("/v1", v1)
 
@@ -110,6 +215,15 @@ func main() {
 	if err := chi.Walk(v1, walkFuncV1); err != nil {
 		log.Printf("V1 router logging err: %s\n", err.Error())
 	} */
+	// Start the feed scraper in the background
+	feedScraper := scraper.NewScraper(config.NewScraperConfig(), store, store)
+	scraperCtx, stopScraper := context.WithCancel(context.Background())
+	scraperDone := make(chan struct{})
+	go func() {
+		feedScraper.Run(scraperCtx)
+		close(scraperDone)
+	}()
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("🚀 Server starting on port %s\n", port)
@@ -132,8 +246,16 @@ func main() {
 		log.Printf("Server shutdown error: %v", err)
 	}
 
-	if err := MongoClient.Disconnect(ctx); err != nil {
-		log.Printf("MongoDB disconnect error: %v", err)
+	// Stop the scraper and wait for the running fetches before closing the database
+	stopScraper()
+	select {
+	case <-scraperDone:
+	case <-ctx.Done():
+		log.Println("Scraper did not stop in time")
+	}
+
+	if err := store.Close(ctx); err != nil {
+		log.Printf("Storage close error: %v", err)
 	}
 
 	log.Println("✅ Server stopped cleanly")
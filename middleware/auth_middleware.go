@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/Aym-Aymen777/RSS-Aggregator/models"
 	"github.com/Aym-Aymen777/RSS-Aggregator/services"
@@ -13,20 +14,45 @@ type contextKey string
 
 const UserContextKey = contextKey("auth")
 
-func AuthMidlleware(tokenService *services.TokenService) func(next http.Handler) http.Handler {
+// AuthMidlleware authenticates the request with an API key from the
+// X-API-Key header or a Bearer header starting with services.APIKeyPrefix,
+// or else with an access token from the cookie or a Bearer header
+func AuthMidlleware(tokenService *services.TokenService, apiKeys *services.APIKeyService) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			log.Printf("🔒 AuthMiddleware: Checking authentication for %s", r.URL.Path)
-			// Extract the token from the Authorization header
+			var bearer string
+			authHeader := r.Header.Get("Authorization")
+			if authHeader != "" && len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+				bearer = authHeader[7:]
+			}
+			apiKey := r.Header.Get("X-API-Key")
+			if apiKey == "" && strings.HasPrefix(bearer, services.APIKeyPrefix) {
+				apiKey = bearer
+			}
+			if apiKey != "" {
+				claims, err := apiKeys.Authenticate(r.Context(), apiKey)
+				if err != nil {
+					if err == services.ErrInvalidAPIKey {
+						log.Printf("🔒 AuthMiddleware: Invalid API key")
+						http.Error(w, "Unauthorized: Invalid API key", http.StatusUnauthorized)
+						return
+					}
+					log.Printf("🔒 AuthMiddleware: Failed to check API key: %v", err)
+					http.Error(w, "Failed to check API key", http.StatusInternalServerError)
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), UserContextKey, claims)))
+				return
+			}
+
+			// Extract the token from the cookie or the Authorization header
 			var tokenString string
 			cookie, err := r.Cookie("access_token")
 			if err == nil {
 				tokenString = cookie.Value
 			} else {
-				authHeader := r.Header.Get("Authorization")
-				if authHeader != "" && len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-					tokenString = authHeader[7:]
-				}
+				tokenString = bearer
 			}
 
 			// Validate the token using the tokenService
@@ -41,6 +67,17 @@ func AuthMidlleware(tokenService *services.TokenService) func(next http.Handler)
 				http.Error(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
 				return
 			}
+			// the session of the token may have been revoked since it was issued
+			if err := tokenService.ValidateSession(r.Context(), claims); err != nil {
+				if err == services.ErrSessionRevoked {
+					log.Printf("🔒 AuthMiddleware: Session revoked for user %s", claims.UserID.Hex())
+					http.Error(w, "Unauthorized: Session revoked", http.StatusUnauthorized)
+					return
+				}
+				log.Printf("🔒 AuthMiddleware: Failed to check session: %v", err)
+				http.Error(w, "Failed to check session", http.StatusInternalServerError)
+				return
+			}
 			// Store claims in request context
 			ctx := context.WithValue(r.Context(), UserContextKey, claims)
 			r = r.WithContext(ctx)
@@ -51,6 +88,24 @@ func AuthMidlleware(tokenService *services.TokenService) func(next http.Handler)
 	}
 }
 
+// RequireSession rejects the requests authenticated with an API key, for the
+// routes that manage credentials: a leaked key can not be used to mint new
+// keys or to take over the account. It must run after AuthMidlleware.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetUserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized: No token provided", http.StatusUnauthorized)
+			return
+		}
+		if !claims.APIKeyID.IsZero() {
+			http.Error(w, "Forbidden: API keys can not be used on this route", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // GetUserFromContext extracts user claims from request context
 func GetUserFromContext(ctx context.Context) (*models.AccessTokenClaims, bool) {
 	user, ok := ctx.Value(UserContextKey).(*models.AccessTokenClaims)
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+)
+
+// RequireRole lets through the users who have one of the roles. The roles
+// come from the access token, a change of roles applies once the token is
+// refreshed. It must run after AuthMidlleware.
+func RequireRole(roles ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetUserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Unauthorized: No token provided", http.StatusUnauthorized)
+				return
+			}
+			for _, role := range roles {
+				if claims.HasRole(role) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			log.Printf("🔒 RequireRole: User %s lacks one of the roles %v for %s", claims.UserID.Hex(), roles, r.URL.Path)
+			http.Error(w, "Forbidden: insufficient role", http.StatusForbidden)
+		})
+	}
+}
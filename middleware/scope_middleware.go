@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+)
+
+// RequireScopes lets through the tokens and API keys that were granted all
+// the scopes, so a leaked read-only token can not change anything. It must
+// run after AuthMidlleware.
+func RequireScopes(scopes ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetUserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Unauthorized: No token provided", http.StatusUnauthorized)
+				return
+			}
+			if !claims.HasScopes(scopes...) {
+				log.Printf("🔒 RequireScopes: User %s lacks the scopes %v for %s", claims.UserID.Hex(), scopes, r.URL.Path)
+				http.Error(w, "Forbidden: insufficient scope", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
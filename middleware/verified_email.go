@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+)
+
+// RequireVerifiedEmail rejects the requests that change data from users who
+// have not verified their email, reads are let through. It must run after
+// AuthMidlleware.
+func RequireVerifiedEmail(users storage.UserStore) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+			claims, ok := GetUserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Unauthorized: No token provided", http.StatusUnauthorized)
+				return
+			}
+			user, err := users.GetUserByID(r.Context(), claims.UserID)
+			if err != nil {
+				if err == storage.ErrNotFound {
+					http.Error(w, "Unauthorized: User not found", http.StatusUnauthorized)
+					return
+				}
+				log.Printf("🔒 RequireVerifiedEmail: Failed to fetch user: %v", err)
+				http.Error(w, "Failed to check email verification", http.StatusInternalServerError)
+				return
+			}
+			if !user.EmailVerified {
+				http.Error(w, "Forbidden: Email address not verified", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
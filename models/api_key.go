@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// APIKey is a long lived credential of a user for scripts, only the SHA-256
+// hash of the key is stored and Prefix is kept to tell the keys apart
+type APIKey struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     bson.ObjectID `bson:"user_id" json:"user_id"`
+	Name       string        `bson:"name" json:"name"`
+	Prefix     string        `bson:"prefix" json:"prefix"`
+	KeyHash    string        `bson:"key_hash" json:"-"`
+	Scopes     []string      `bson:"scopes" json:"scopes"`
+	ExpiresAt  *time.Time    `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time    `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time    `bson:"revoked_at,omitempty" json:"-"`
+	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// Active reports whether the key can still be used
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -1,15 +1,19 @@
 package models
 
 import (
-    "time"
-    "go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type Auth struct {
-    ID        bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-    Username  string        `bson:"username" json:"username"`
-    Email     string        `bson:"email" json:"email"`
-    Password  string        `bson:"password" json:"-"`
-    CreatedAt time.Time     `bson:"created_at" json:"created_at"`
-    UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
-}
\ No newline at end of file
+	ID              bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Username        string        `bson:"username" json:"username"`
+	Email           string        `bson:"email" json:"email"`
+	Password        string        `bson:"password" json:"-"`
+	Roles           []string      `bson:"roles" json:"roles"`
+	EmailVerified   bool          `bson:"email_verified" json:"email_verified"`
+	EmailVerifiedAt *time.Time    `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time     `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// EmailVerification is a pending confirmation of the email of a user, ID is
+// the SHA-256 hash of the token sent to the address
+type EmailVerification struct {
+	ID        string        `bson:"_id" json:"-"`
+	UserID    bson.ObjectID `bson:"user_id" json:"user_id"`
+	ExpiresAt time.Time     `bson:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time    `bson:"used_at,omitempty" json:"used_at,omitempty"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
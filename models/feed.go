@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Feed struct {
+	ID            bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	URL           string        `bson:"url" json:"url"`
+	Title         string        `bson:"title" json:"title"`
+	SiteLink      string        `bson:"site_link" json:"site_link"`
+	Category      string        `bson:"category,omitempty" json:"category,omitempty"`
+	UserID        bson.ObjectID `bson:"user_id" json:"user_id"`
+	LastFetchedAt *time.Time    `bson:"last_fetched_at" json:"last_fetched_at"`
+	LastStatus    int           `bson:"last_status,omitempty" json:"last_status,omitempty"`
+	ETag          string        `bson:"etag" json:"etag,omitempty"`
+	LastModified  string        `bson:"last_modified" json:"last_modified,omitempty"`
+	ErrorCount    int           `bson:"error_count" json:"error_count"`
+	CreatedAt     time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time     `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FeedFollow links a user to a feed whose posts they want to read
+type FeedFollow struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    bson.ObjectID `bson:"user_id" json:"user_id"`
+	FeedID    bson.ObjectID `bson:"feed_id" json:"feed_id"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Identity links a user to their account at an OpenID Connect provider, the
+// provider knows the user by Subject
+type Identity struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    bson.ObjectID `bson:"user_id" json:"user_id"`
+	Issuer    string        `bson:"issuer" json:"issuer"`
+	Subject   string        `bson:"subject" json:"subject"`
+	Email     string        `bson:"email" json:"email"` // email the provider gave when the identity was linked
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+// JSONFeedVersion is the version URL of the JSON Feed spec we produce
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// JSONFeed is a JSON Feed 1.1 document (https://www.jsonfeed.org/version/1.1/)
+type JSONFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url,omitempty"`
+	FeedURL     string           `json:"feed_url,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Authors     []JSONFeedAuthor `json:"authors,omitempty"`
+	Author      *JSONFeedAuthor  `json:"author,omitempty"` // deprecated in 1.1, still read from 1.0 feeds
+	Items       []JSONFeedItem   `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	ExternalURL   string               `json:"external_url,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	ContentHTML   string               `json:"content_html,omitempty"`
+	ContentText   string               `json:"content_text,omitempty"`
+	Summary       string               `json:"summary,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	DateModified  string               `json:"date_modified,omitempty"`
+	Authors       []JSONFeedAuthor     `json:"authors,omitempty"`
+	Author        *JSONFeedAuthor      `json:"author,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Attachments   []JSONFeedAttachment `json:"attachments,omitempty"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type JSONFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	Title       string `json:"title,omitempty"`
+	SizeInBytes int64  `json:"size_in_bytes,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MFA is the TOTP second factor of a user, it is pending until the user
+// confirms it with a first code
+type MFA struct {
+	UserID    bson.ObjectID `bson:"_id" json:"user_id"`
+	Secret    string        `bson:"secret" json:"-"`
+	Enabled   bool          `bson:"enabled" json:"enabled"`
+	EnabledAt *time.Time    `bson:"enabled_at,omitempty" json:"enabled_at,omitempty"`
+	// LastStep is the TOTP time step of the last accepted code, a code is
+	// only accepted once
+	LastStep int64 `bson:"last_step" json:"-"`
+	// RecoveryCodes are the SHA-256 hashes of the unused recovery codes
+	RecoveryCodes  []string   `bson:"recovery_codes" json:"-"`
+	FailedAttempts int        `bson:"failed_attempts" json:"-"`
+	LastFailureAt  *time.Time `bson:"last_failure_at,omitempty" json:"-"`
+	CreatedAt      time.Time  `bson:"created_at" json:"created_at"`
+}
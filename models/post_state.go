@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// PostState is the read and starred state of a post for one user, a post
+// without state is unread and not starred
+type PostState struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    bson.ObjectID `bson:"user_id" json:"user_id"`
+	PostID    bson.ObjectID `bson:"post_id" json:"post_id"`
+	FeedID    bson.ObjectID `bson:"feed_id,omitempty" json:"feed_id,omitempty"`
+	Read      bool          `bson:"read" json:"read"`
+	ReadAt    *time.Time    `bson:"read_at,omitempty" json:"read_at,omitempty"`
+	Starred   bool          `bson:"starred" json:"starred"`
+	StarredAt *time.Time    `bson:"starred_at,omitempty" json:"starred_at,omitempty"`
+	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
+}
@@ -8,9 +8,26 @@ import (
 
 type Post struct {
 	ID          bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Title       string `bson:"title" json:"title"`
-	Description string `bson:"description" json:"description"`
-	Link        string `bson:"link" json:"link"`
-	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
-}
\ No newline at end of file
+	FeedID      bson.ObjectID `bson:"feed_id,omitempty" json:"feed_id,omitempty"`
+	UserID      bson.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"` // owner of a post created by hand
+	GUID        string        `bson:"guid,omitempty" json:"guid,omitempty"`
+	Title       string        `bson:"title" json:"title"`
+	Description string        `bson:"description" json:"description"`
+	Content     string        `bson:"content,omitempty" json:"content,omitempty"`
+	Link        string        `bson:"link" json:"link"`
+	Author      string        `bson:"author,omitempty" json:"author,omitempty"`
+	Categories  []string      `bson:"categories,omitempty" json:"categories,omitempty"`
+	Enclosure   *Enclosure    `bson:"enclosure,omitempty" json:"enclosure,omitempty"`
+	PublishedAt time.Time     `bson:"published_at" json:"published_at"`
+	ItemKey     string        `bson:"item_key,omitempty" json:"-"`     // unique per feed, see storage.ItemKey
+	ContentHash string        `bson:"content_hash,omitempty" json:"-"` // detects edited items
+	CreatedAt   time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// Enclosure is a media file attached to a post (podcast episode, image...)
+type Enclosure struct {
+	URL    string `bson:"url" json:"url"`
+	Type   string `bson:"type,omitempty" json:"type,omitempty"`
+	Length int64  `bson:"length,omitempty" json:"length,omitempty"`
+}
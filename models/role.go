@@ -0,0 +1,29 @@
+package models
+
+import "slices"
+
+// Roles of the users, every user has RoleUser
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// AllRoles lists every known role
+var AllRoles = []string{RoleUser, RoleAdmin}
+
+func ValidRole(role string) bool {
+	return slices.Contains(AllRoles, role)
+}
+
+// UserRoles returns the roles of the user, records created before roles
+// existed have none stored and are plain users
+func (a *Auth) UserRoles() []string {
+	if len(a.Roles) == 0 {
+		return []string{RoleUser}
+	}
+	return a.Roles
+}
+
+func (c *AccessTokenClaims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
@@ -0,0 +1,40 @@
+package models
+
+import "slices"
+
+// Scopes limit what a token or an API key can be used for
+const (
+	ScopePostsRead  = "posts:read"
+	ScopePostsWrite = "posts:write"
+	ScopeFeedsWrite = "feeds:write"
+	ScopeAdmin      = "admin"
+)
+
+// AllScopes lists every known scope
+var AllScopes = []string{ScopePostsRead, ScopePostsWrite, ScopeFeedsWrite, ScopeAdmin}
+
+// DefaultScopes are the scopes of every user
+var DefaultScopes = []string{ScopePostsRead, ScopePostsWrite, ScopeFeedsWrite}
+
+func ValidScope(scope string) bool {
+	return slices.Contains(AllScopes, scope)
+}
+
+// ScopesForRoles returns the scopes users with the roles may be granted
+func ScopesForRoles(roles []string) []string {
+	scopes := slices.Clone(DefaultScopes)
+	if slices.Contains(roles, RoleAdmin) {
+		scopes = append(scopes, ScopeAdmin)
+	}
+	return scopes
+}
+
+// HasScopes reports whether the token was granted all the scopes
+func (c *AccessTokenClaims) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(c.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Session is a login of a user on a device. The refresh tokens of the session
+// carry its id as their family, and it expires with the last of them.
+type Session struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     bson.ObjectID `bson:"user_id" json:"user_id"`
+	UserAgent  string        `bson:"user_agent" json:"user_agent"`
+	IP         string        `bson:"ip" json:"ip"`
+	Scopes     []string      `bson:"scopes" json:"scopes"` // granted at login, none for sessions older than scopes
+	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
+	LastUsedAt time.Time     `bson:"last_used_at" json:"last_used_at"`
+	ExpiresAt  time.Time     `bson:"expires_at" json:"expires_at"`
+	RevokedAt  *time.Time    `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the session can still be used
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -1,19 +1,51 @@
 package models
 
 import (
+	"time"
+
 	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type AccessTokenClaims struct {
-	UserID bson.ObjectID `json:"_id"`
-	Email  string        `json:"email"`
+	UserID    bson.ObjectID `json:"_id"`
+	Email     string        `json:"email"`
+	SessionID bson.ObjectID `json:"sid"`
+	// Roles are the roles of the user when the token was issued
+	Roles []string `json:"roles"`
+	// Scopes limit what the token can be used for
+	Scopes []string `json:"scopes"`
+	// APIKeyID is set instead of SessionID when the request was
+	// authenticated with an API key, it is never part of a token
+	APIKeyID bson.ObjectID `json:"-"`
 	jwt.RegisteredClaims
 }
 
+// RefreshTokenClaims carry the jti of the token in RegisteredClaims.ID and the
+// family it was rotated from, which is the hex id of its session
 type RefreshTokenClaims struct {
+	UserID   bson.ObjectID `json:"_id"`
+	Email    string        `json:"email"`
+	FamilyID string        `json:"fid"`
+	jwt.RegisteredClaims
+}
+
+// MFAChallengeClaims are carried by the short lived token a login returns
+// when the user has to give a second factor
+type MFAChallengeClaims struct {
 	UserID bson.ObjectID `json:"_id"`
 	Email  string        `json:"email"`
+	// Scopes are the scopes asked for at the password step
+	Scopes []string `json:"scopes,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// OIDCStateClaims are carried by the cookie that ties the callback of an
+// OpenID Connect login to the browser that started it
+type OIDCStateClaims struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"cv"`
 	jwt.RegisteredClaims
 }
 
@@ -22,3 +54,16 @@ type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 }
+
+// RefreshToken is the server side record of an issued refresh token, ID is the
+// jti claim. Every refresh replaces the token with a new one of the same
+// family, the family is the session opened by the login.
+type RefreshToken struct {
+	ID         string        `bson:"_id" json:"id"`
+	UserID     bson.ObjectID `bson:"user_id" json:"user_id"`
+	FamilyID   string        `bson:"family_id" json:"family_id"`
+	ReplacedBy string        `bson:"replaced_by,omitempty" json:"replaced_by,omitempty"` // jti of the token it was rotated to
+	ExpiresAt  time.Time     `bson:"expires_at" json:"expires_at"`
+	RevokedAt  *time.Time    `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
+}
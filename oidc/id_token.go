@@ -0,0 +1,58 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingMethods are the algorithms ID tokens are accepted with, symmetric
+// ones and none would not prove the provider signed the token
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// IDTokenClaims are the claims of an ID token the service uses
+type IDTokenClaims struct {
+	Nonce             string `json:"nonce"`
+	AuthorizedParty   string `json:"azp,omitempty"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	jwt.RegisteredClaims
+}
+
+// VerifyIDToken checks the signature of the ID token against the keys of the
+// provider, that it was issued by the provider for this client and not
+// expired, and that it carries the nonce of the login it ends
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*IDTokenClaims, error) {
+	var claims IDTokenClaims
+	_, err := jwt.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id token: %w", err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("id token: no subject")
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("id token: nonce does not match")
+	}
+	// a token for several clients must name this one as the party it was issued to
+	if (len(claims.Audience) > 1 || claims.AuthorizedParty != "") && claims.AuthorizedParty != p.clientID {
+		return nil, errors.New("id token: issued to another client")
+	}
+	return &claims, nil
+}
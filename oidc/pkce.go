@@ -0,0 +1,22 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// NewCodeVerifier returns a random PKCE code verifier, it also makes a good
+// state or nonce
+func NewCodeVerifier() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// CodeChallenge returns the S256 challenge of a code verifier, it is sent
+// with the authorization request and the verifier with the code exchange
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,189 @@
+// Package oidc implements the client side of the OpenID Connect authorization
+// code flow with PKCE: discovery of the provider, the authorization URL, the
+// code exchange and the validation of ID tokens against the keys the
+// provider publishes.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metadata is the part of the discovery document of a provider the flow uses
+type Metadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	CodeChallengeMethods  []string `json:"code_challenge_methods_supported"`
+}
+
+// Token is the response of the token endpoint
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Provider is an OpenID Connect provider the service is registered at as a
+// client. The discovery document and the keys are fetched on first use and
+// kept, so the provider does not have to be up when the service starts.
+type Provider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	client       *http.Client
+
+	mu            sync.Mutex
+	metadata      *Metadata
+	keys          map[string]any
+	keysFetchedAt time.Time
+}
+
+func NewProvider(issuer, clientID, clientSecret, redirectURL string, scopes []string) *Provider {
+	if !slices.Contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	return &Provider{
+		issuer:       issuer,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		scopes:       scopes,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Issuer returns the issuer the ID tokens of the provider carry
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// AuthCodeURL returns the URL of the provider the user is sent to for logging
+// in, the provider sends them back to the redirect URL with a code and state
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.clientID},
+		"redirect_uri":          {p.redirectURL},
+		"scope":                 {strings.Join(p.scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange trades the code of the callback and the PKCE verifier it was
+// requested with for the tokens of the user
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.redirectURL},
+		"client_id":     {p.clientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		json.Unmarshal(body, &failure)
+		return nil, fmt.Errorf("token request: status %d: %s %s", resp.StatusCode, failure.Error, failure.Description)
+	}
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("token response: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("token response: no id_token")
+	}
+	return &token, nil
+}
+
+// discover returns the discovery document of the provider, it is only kept
+// once it was fetched successfully
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+	var metadata Metadata
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.issuer, "/")+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	// a document served for another issuer would let it sign our ID tokens
+	if metadata.Issuer != p.issuer {
+		return nil, fmt.Errorf("discovery: issuer %q does not match %q", metadata.Issuer, p.issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("discovery: missing endpoints")
+	}
+	if len(metadata.CodeChallengeMethods) > 0 && !slices.Contains(metadata.CodeChallengeMethods, "S256") {
+		return nil, errors.New("discovery: provider does not support S256 code challenges")
+	}
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
@@ -0,0 +1,145 @@
+// Package opml reads and writes OPML 2.0 subscription lists.
+package opml
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+)
+
+type Document struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+	OwnerEmail  string `xml:"ownerEmail,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Category string    `xml:"category,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Subscription is a feed outline with the folder it was found in
+type Subscription struct {
+	Title    string
+	XMLURL   string
+	HTMLURL  string
+	Category string // nested folders are joined with "/"
+}
+
+func Parse(r io.Reader) (*Document, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	var doc Document
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// Subscriptions flattens the outline tree, outlines with children and no
+// xmlUrl are folders
+func (d *Document) Subscriptions() []Subscription {
+	var subs []Subscription
+	collectSubscriptions(d.Body.Outlines, "", &subs)
+	return subs
+}
+
+func collectSubscriptions(outlines []Outline, folder string, subs *[]Subscription) {
+	for _, outline := range outlines {
+		title := strings.TrimSpace(outline.Title)
+		if title == "" {
+			title = strings.TrimSpace(outline.Text)
+		}
+		if outline.XMLURL == "" && len(outline.Outlines) > 0 {
+			child := title
+			if folder != "" {
+				child = folder + "/" + title
+			}
+			collectSubscriptions(outline.Outlines, child, subs)
+			continue
+		}
+		category := folder
+		if category == "" {
+			// OPML 2.0 category attribute: comma separated slash delimited paths
+			category = strings.Trim(strings.SplitN(outline.Category, ",", 2)[0], " /")
+		}
+		*subs = append(*subs, Subscription{
+			Title:    title,
+			XMLURL:   strings.TrimSpace(outline.XMLURL),
+			HTMLURL:  strings.TrimSpace(outline.HTMLURL),
+			Category: category,
+		})
+	}
+}
+
+// New builds an OPML 2.0 document, subscriptions are grouped in nested folders
+// following their category path
+func New(title string, subs []Subscription) *Document {
+	doc := &Document{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, sub := range subs {
+		outlines := &doc.Body.Outlines
+		for _, folder := range strings.Split(sub.Category, "/") {
+			if folder = strings.TrimSpace(folder); folder != "" {
+				outlines = folderOutlines(outlines, folder)
+			}
+		}
+		*outlines = append(*outlines, Outline{
+			Text:    sub.Title,
+			Title:   sub.Title,
+			Type:    "rss",
+			XMLURL:  sub.XMLURL,
+			HTMLURL: sub.HTMLURL,
+		})
+	}
+	return doc
+}
+
+// folderOutlines returns the children of the folder with the given name,
+// the folder is created when missing
+func folderOutlines(outlines *[]Outline, name string) *[]Outline {
+	for i := range *outlines {
+		if (*outlines)[i].XMLURL == "" && (*outlines)[i].Text == name {
+			return &(*outlines)[i].Outlines
+		}
+	}
+	*outlines = append(*outlines, Outline{Text: name, Title: name})
+	return &(*outlines)[len(*outlines)-1].Outlines
+}
+
+// Write encodes the document with its XML declaration
+func (d *Document) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(d); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
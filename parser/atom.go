@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+const nsAtom = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	Title    atomText     `xml:"title"`
+	Subtitle atomText     `xml:"subtitle"`
+	Links    []atomLink   `xml:"link"`
+	Authors  []atomPerson `xml:"author"`
+	Entries  []atomEntry  `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      atomText       `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Summary    atomText       `xml:"summary"`
+	Content    atomText       `xml:"content"`
+	Authors    []atomPerson   `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// atomText is a text construct, its type is text (the default), html or xhtml
+type atomText struct {
+	Type     string `xml:"type,attr"`
+	Text     string `xml:",chardata"`
+	InnerXML string `xml:",innerxml"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type atomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+func parseAtom(data []byte) (*Feed, error) {
+	var doc atomFeed
+	if err := newDecoder(data).Decode(&doc); err != nil {
+		return nil, err
+	}
+	feed := &Feed{
+		Title:       doc.Title.value(),
+		Link:        atomAlternateLink(doc.Links),
+		Description: doc.Subtitle.value(),
+	}
+	for _, entry := range doc.Entries {
+		post := entry.toPost()
+		// the feed author applies to the entries without their own
+		if post.Author == "" {
+			post.Author = atomAuthor(doc.Authors)
+		}
+		feed.Items = append(feed.Items, post)
+	}
+	return feed, nil
+}
+
+func (entry atomEntry) toPost() models.Post {
+	post := models.Post{
+		GUID:        strings.TrimSpace(entry.ID),
+		Title:       entry.Title.value(),
+		Description: entry.Summary.value(),
+		Content:     entry.Content.value(),
+		Link:        atomAlternateLink(entry.Links),
+		Author:      atomAuthor(entry.Authors),
+	}
+	if published, ok := parseDate(firstNonEmpty(entry.Published, entry.Updated)); ok {
+		post.PublishedAt = published
+	}
+	for _, category := range entry.Categories {
+		if name := firstNonEmpty(category.Label, category.Term); name != "" {
+			post.Categories = append(post.Categories, name)
+		}
+	}
+	for _, link := range entry.Links {
+		if link.Rel == "enclosure" && link.Href != "" {
+			length, _ := strconv.ParseInt(strings.TrimSpace(link.Length), 10, 64)
+			post.Enclosure = &models.Enclosure{
+				URL:    strings.TrimSpace(link.Href),
+				Type:   link.Type,
+				Length: length,
+			}
+			break
+		}
+	}
+	return post
+}
+
+// value returns the content of a text construct, html and xhtml are
+// returned as markup
+func (t atomText) value() string {
+	if strings.EqualFold(t.Type, "xhtml") {
+		return strings.TrimSpace(unwrapXHTMLDiv(t.InnerXML))
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// unwrapXHTMLDiv removes the <div> wrapping xhtml content required by RFC 4287
+func unwrapXHTMLDiv(markup string) string {
+	markup = strings.TrimSpace(markup)
+	if !strings.HasPrefix(markup, "<div") && !strings.HasPrefix(markup, "<xhtml:div") {
+		return markup
+	}
+	start := strings.Index(markup, ">")
+	end := strings.LastIndex(markup, "</")
+	if start < 0 || end < start {
+		return markup
+	}
+	return markup[start+1 : end]
+}
+
+// atomAlternateLink picks the link of the html page, a link without rel is an alternate one
+func atomAlternateLink(links []atomLink) string {
+	fallback := ""
+	for _, link := range links {
+		if link.Rel != "" && link.Rel != "alternate" {
+			continue
+		}
+		if link.Type == "" || link.Type == "text/html" {
+			return strings.TrimSpace(link.Href)
+		}
+		if fallback == "" {
+			fallback = strings.TrimSpace(link.Href)
+		}
+	}
+	return fallback
+}
+
+func atomAuthor(authors []atomPerson) string {
+	for _, author := range authors {
+		if name := firstNonEmpty(author.Name, author.Email); name != "" {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+func TestParseAtom(t *testing.T) {
+	runFeedTests(t, []feedTest{
+		{
+			name: "atom 1.0",
+			doc: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Example</title>
+	<subtitle type="html">&lt;b&gt;News&lt;/b&gt;</subtitle>
+	<link rel="self" href="https://example.com/atom.xml"/>
+	<link href="https://example.com/"/>
+	<author><name>Site</name></author>
+	<entry>
+		<id>tag:example.com,2024:1</id>
+		<title type="text">First</title>
+		<link rel="alternate" type="application/json" href="https://example.com/1.json"/>
+		<link rel="alternate" type="text/html" href="https://example.com/1"/>
+		<link rel="enclosure" type="audio/mpeg" length="5678" href="https://example.com/1.mp3"/>
+		<published>2024-03-01T10:00:00+01:00</published>
+		<updated>2024-03-02T10:00:00Z</updated>
+		<summary>Short</summary>
+		<content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Hello <b>world</b></p></div></content>
+		<author><name>Jane</name></author>
+		<category term="go" label="Go"/>
+		<category term="news"/>
+	</entry>
+	<entry>
+		<id>tag:example.com,2024:2</id>
+		<title type="html">Second &amp;amp; last</title>
+		<link href="https://example.com/2"/>
+		<updated>2024-03-03T10:00:00Z</updated>
+		<content type="html">&lt;p&gt;Body&lt;/p&gt;</content>
+	</entry>
+</feed>`,
+			want: Feed{
+				Title:       "Example",
+				Link:        "https://example.com/",
+				Description: "<b>News</b>",
+				Items: []models.Post{
+					{
+						GUID:        "tag:example.com,2024:1",
+						Title:       "First",
+						Description: "Short",
+						Content:     "<p>Hello <b>world</b></p>",
+						Link:        "https://example.com/1",
+						Author:      "Jane",
+						Categories:  []string{"Go", "news"},
+						Enclosure:   &models.Enclosure{URL: "https://example.com/1.mp3", Type: "audio/mpeg", Length: 5678},
+						PublishedAt: date(2024, 3, 1, 9, 0, 0),
+					},
+					{
+						GUID:        "tag:example.com,2024:2",
+						Title:       "Second &amp; last",
+						Content:     "<p>Body</p>",
+						Link:        "https://example.com/2",
+						Author:      "Site",
+						PublishedAt: date(2024, 3, 3, 10, 0, 0),
+					},
+				},
+			},
+		},
+		{
+			name: "prefixed xhtml",
+			doc: `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:xhtml="http://www.w3.org/1999/xhtml">
+	<entry>
+		<title type="xhtml"><xhtml:div>A <xhtml:em>title</xhtml:em></xhtml:div></title>
+		<link rel="enclosure" href="https://example.com/a.png"/>
+		<link rel="related" href="https://example.com/related"/>
+	</entry>
+</feed>`,
+			want: Feed{
+				Items: []models.Post{{
+					Title:     "A <xhtml:em>title</xhtml:em>",
+					Enclosure: &models.Enclosure{URL: "https://example.com/a.png"},
+				}},
+			},
+		},
+	})
+}
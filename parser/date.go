@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"strings"
+	"time"
+)
+
+// dateLayouts lists the RFC 822 variants used by RSS feeds, the most common first
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 02 Jan 2006 15:04 -0700",
+	"Mon, 02 Jan 2006 15:04 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"02 Jan 2006 15:04:05 -0700",
+	"02 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 January 2006 15:04:05 -0700",
+	"Mon, 2 January 2006 15:04:05 MST",
+	"Monday, 02-Jan-06 15:04:05 MST",
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// zoneOffsets are the time zone names allowed by RFC 822, time.Parse
+// only knows their offset when they match the local zone
+var zoneOffsets = map[string]int{
+	"UTC": 0,
+	"GMT": 0,
+	"EST": -5 * 3600,
+	"EDT": -4 * 3600,
+	"CST": -6 * 3600,
+	"CDT": -5 * 3600,
+	"MST": -7 * 3600,
+	"MDT": -6 * 3600,
+	"PST": -8 * 3600,
+	"PDT": -7 * 3600,
+}
+
+// parseDate parses the dates found in feeds, it returns false when none of the
+// known layouts matches
+func parseDate(value string) (time.Time, bool) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+	// time.Parse wants at least three letters in a zone name
+	if last := len(fields) - 1; last > 0 && (fields[last] == "UT" || fields[last] == "Z") {
+		fields[last] = "GMT"
+	}
+	value = strings.Join(fields, " ")
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		name, offset := t.Zone()
+		if known, ok := zoneOffsets[strings.ToUpper(name)]; ok && offset != known {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.FixedZone(name, known))
+		}
+		return t.UTC(), true
+	}
+	return time.Time{}, false
+}
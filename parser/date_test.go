@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	want := date(2003, 6, 10, 9, 41, 1)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		// RFC 1123 and RFC 822, with numeric and named zones
+		{"Tue, 10 Jun 2003 09:41:01 GMT", want},
+		{"Tue, 10 Jun 2003 09:41:01 UT", want},
+		{"Tue, 10 Jun 2003 09:41:01 Z", want},
+		{"Tue, 10 Jun 2003 09:41:01 +0000", want},
+		{"Tue, 10 Jun 2003 11:41:01 +0200", want},
+		{"Tue, 10 Jun 2003 05:41:01 EDT", want},
+		{"Tue, 10 Jun 2003 04:41:01 EST", want},
+		{"Tue, 10 Jun 2003 04:41:01 CDT", want},
+		{"Tue, 10 Jun 2003 02:41:01 PDT", want},
+		{"Tue, 10 Jun 2003 01:41:01 PST", want},
+		{"Tue, 10 Jun 2003 03:41:01 MDT", want},
+		{"Tue, 10 Jun 2003 02:41:01 MST", want},
+		{"Tue, 10 Jun 2003 03:41:01 CST", want},
+		{"10 Jun 03 09:41 GMT", date(2003, 6, 10, 9, 41, 0)},
+		{"10 Jun 03 05:41 -0400", date(2003, 6, 10, 9, 41, 0)},
+		// the variants feeds use in the wild
+		{"Tue, 3 Jun 2003 09:41:01 GMT", date(2003, 6, 3, 9, 41, 1)},
+		{"Tue, 3 Jun 2003 11:41:01 +0200", date(2003, 6, 3, 9, 41, 1)},
+		{"Tue, 10 Jun 2003 09:41 GMT", date(2003, 6, 10, 9, 41, 0)},
+		{"Tue, 3 Jun 2003 09:41 -0000", date(2003, 6, 3, 9, 41, 0)},
+		{"10 Jun 2003 09:41:01 GMT", want},
+		{"3 Jun 2003 11:41:01 +0200", date(2003, 6, 3, 9, 41, 1)},
+		{"Tue, 10 June 2003 09:41:01 GMT", want},
+		{"Tuesday, 10-Jun-03 09:41:01 GMT", want},
+		{"  Tue,  10 Jun 2003\n09:41:01 GMT ", want},
+		// ISO 8601
+		{"2003-06-10T09:41:01Z", want},
+		{"2003-06-10T11:41:01+02:00", want},
+		{"2003-06-10T09:41:01.5Z", want.Add(500 * time.Millisecond)},
+		{"2003-06-10T09:41:01", want},
+		{"2003-06-10 04:41:01 -0500", want},
+		{"2003-06-10 09:41:01", want},
+		{"2003-06-10", date(2003, 6, 10, 0, 0, 0)},
+	}
+	for _, tt := range tests {
+		got, ok := parseDate(tt.value)
+		if !ok || !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("parseDate(%q) = %v, %v, want %v", tt.value, got, ok, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "yesterday", "10/06/2003", "Tue, 10 Jun 2003 25:00:00 GMT"} {
+		if got, ok := parseDate(value); ok {
+			t.Errorf("parseDate(%q) = %v, want no date", value, got)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+func parseJSONFeed(data []byte) (*Feed, error) {
+	var doc models.JSONFeed
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, errors.New("not a JSON Feed document: missing version")
+	}
+	feed := &Feed{
+		Title:       strings.TrimSpace(doc.Title),
+		Link:        strings.TrimSpace(doc.HomePageURL),
+		Description: strings.TrimSpace(doc.Description),
+	}
+	feedAuthor := jsonFeedAuthor(doc.Authors, doc.Author)
+	for _, item := range doc.Items {
+		post := jsonFeedItemToPost(item)
+		if post.Author == "" {
+			post.Author = feedAuthor
+		}
+		feed.Items = append(feed.Items, post)
+	}
+	return feed, nil
+}
+
+func jsonFeedItemToPost(item models.JSONFeedItem) models.Post {
+	post := models.Post{
+		GUID:        strings.TrimSpace(item.ID),
+		Title:       strings.TrimSpace(item.Title),
+		Description: strings.TrimSpace(item.Summary),
+		Content:     firstNonEmpty(item.ContentHTML, item.ContentText),
+		Link:        firstNonEmpty(item.URL, item.ExternalURL),
+		Author:      jsonFeedAuthor(item.Authors, item.Author),
+		Categories:  item.Tags,
+	}
+	if post.Description == "" {
+		post.Description = strings.TrimSpace(item.ContentText)
+	}
+	if published, ok := parseDate(firstNonEmpty(item.DatePublished, item.DateModified)); ok {
+		post.PublishedAt = published
+	}
+	if len(item.Attachments) > 0 && item.Attachments[0].URL != "" {
+		post.Enclosure = &models.Enclosure{
+			URL:    item.Attachments[0].URL,
+			Type:   item.Attachments[0].MimeType,
+			Length: item.Attachments[0].SizeInBytes,
+		}
+	}
+	return post
+}
+
+func jsonFeedAuthor(authors []models.JSONFeedAuthor, legacy *models.JSONFeedAuthor) string {
+	for _, author := range authors {
+		if name := strings.TrimSpace(author.Name); name != "" {
+			return name
+		}
+	}
+	if legacy != nil {
+		return strings.TrimSpace(legacy.Name)
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+func TestParseJSONFeed(t *testing.T) {
+	runFeedTests(t, []feedTest{
+		{
+			name: "json feed 1.1",
+			doc: `{
+	"version": "https://jsonfeed.org/version/1.1",
+	"title": "Example",
+	"home_page_url": "https://example.com/",
+	"description": "News",
+	"authors": [{"name": ""}, {"name": "Site"}],
+	"items": [
+		{
+			"id": "1",
+			"url": "https://example.com/1",
+			"title": "First",
+			"content_html": "<p>Hello</p>",
+			"content_text": "Hello",
+			"date_published": "2024-03-01T10:00:00+01:00",
+			"authors": [{"name": "Jane"}],
+			"tags": ["go", "json"],
+			"attachments": [{"url": "https://example.com/1.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 99}]
+		},
+		{
+			"id": "2",
+			"external_url": "https://elsewhere.com/",
+			"summary": "Summary",
+			"content_text": "Text",
+			"date_modified": "2024-03-02T10:00:00Z"
+		}
+	]
+}`,
+			want: Feed{
+				Title:       "Example",
+				Link:        "https://example.com/",
+				Description: "News",
+				Items: []models.Post{
+					{
+						GUID:        "1",
+						Title:       "First",
+						Description: "Hello",
+						Content:     "<p>Hello</p>",
+						Link:        "https://example.com/1",
+						Author:      "Jane",
+						Categories:  []string{"go", "json"},
+						Enclosure:   &models.Enclosure{URL: "https://example.com/1.mp3", Type: "audio/mpeg", Length: 99},
+						PublishedAt: date(2024, 3, 1, 9, 0, 0),
+					},
+					{
+						GUID:        "2",
+						Description: "Summary",
+						Content:     "Text",
+						Link:        "https://elsewhere.com/",
+						Author:      "Site",
+						PublishedAt: date(2024, 3, 2, 10, 0, 0),
+					},
+				},
+			},
+		},
+		{
+			// 1.0 has a single author instead of the authors array
+			name: "json feed 1.0",
+			doc: "\xef\xbb\xbf" + `  {
+	"version": "https://jsonfeed.org/version/1",
+	"title": "Old",
+	"author": {"name": "Site"},
+	"items": [
+		{"id": "1", "content_text": "Text", "author": {"name": "Jane"}},
+		{"id": "2", "content_html": "<p>Html</p>"}
+	]
+}`,
+			want: Feed{
+				Title: "Old",
+				Items: []models.Post{
+					{GUID: "1", Description: "Text", Content: "Text", Author: "Jane"},
+					{GUID: "2", Content: "<p>Html</p>", Author: "Site"},
+				},
+			},
+		},
+	})
+}
+
+func TestParseJSONFeedWithoutVersion(t *testing.T) {
+	if _, err := Parse([]byte(`{"title": "Not a feed", "items": []}`)); err == nil {
+		t.Error("Parse accepted a JSON document without version")
+	}
+}
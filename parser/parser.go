@@ -0,0 +1,111 @@
+// Package parser decodes feed documents into models.Post values.
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+var ErrUnknownFormat = errors.New("unknown feed format")
+
+// Feed is the result of parsing a feed document
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Items       []models.Post
+}
+
+// Parse decodes a feed document, JSON Feed documents are recognized by their
+// leading brace and XML formats by their root element
+func Parse(data []byte) (*Feed, error) {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseJSONFeed(trimmed)
+	}
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case root.Local == "rss":
+		return parseRSS(data)
+	case root.Local == "feed" && (root.Space == nsAtom || root.Space == ""):
+		return parseAtom(data)
+	case root.Local == "RDF" && root.Space == nsRDF:
+		return parseRDF(data)
+	}
+	return nil, fmt.Errorf("%w: root element <%s>", ErrUnknownFormat, root.Local)
+}
+
+// rootElement returns the name of the first element of an XML document
+func rootElement(data []byte) (xml.Name, error) {
+	decoder := newDecoder(data)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return xml.Name{}, ErrUnknownFormat
+			}
+			return xml.Name{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+func newDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = charsetReader
+	return decoder
+}
+
+// charsetReader converts the legacy charsets still found in the wild to UTF-8
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1":
+		return decodeSingleByte(input, nil)
+	case "windows-1252", "cp1252":
+		return decodeSingleByte(input, &cp1252)
+	}
+	return nil, fmt.Errorf("unsupported charset %q", charset)
+}
+
+// cp1252 maps the bytes 0x80 to 0x9f of windows-1252, which puts printable
+// characters where latin1 has C1 controls. The bytes windows-1252 leaves
+// undefined keep their latin1 value.
+var cp1252 = [32]rune{
+	'\u20ac', '\u0081', '\u201a', '\u0192', '\u201e', '\u2026', '\u2020', '\u2021',
+	'\u02c6', '\u2030', '\u0160', '\u2039', '\u0152', '\u008d', '\u017d', '\u008f',
+	'\u0090', '\u2018', '\u2019', '\u201c', '\u201d', '\u2022', '\u2013', '\u2014',
+	'\u02dc', '\u2122', '\u0161', '\u203a', '\u0153', '\u009d', '\u017e', '\u0178',
+}
+
+// decodeSingleByte converts a single byte charset to UTF-8. Every byte is the
+// code point with the same value, as in latin1, except the bytes 0x80 to 0x9f
+// when high gives them other characters.
+func decodeSingleByte(input io.Reader, high *[32]rune) (io.Reader, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+		if high != nil && b >= 0x80 && b <= 0x9f {
+			runes[i] = high[b-0x80]
+		}
+	}
+	return strings.NewReader(string(runes)), nil
+}
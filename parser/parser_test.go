@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// feedTest is a document and the feed it must parse to
+type feedTest struct {
+	name string
+	doc  string
+	want Feed
+}
+
+func runFeedTests(t *testing.T, tests []feedTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Parse returned\n%s\nwant\n%s", dump(*got), dump(tt.want))
+			}
+		})
+	}
+}
+
+func dump(feed Feed) string {
+	var out strings.Builder
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(feed)
+	return out.String()
+}
+
+func date(year int, month time.Month, day, hour, min, sec int) time.Time {
+	return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	for _, doc := range []string{`<html><body/></html>`, ``, `<?xml version="1.0"?>`} {
+		if _, err := Parse([]byte(doc)); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("Parse(%q) = %v, want ErrUnknownFormat", doc, err)
+		}
+	}
+}
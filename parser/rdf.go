@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+const nsRDF = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+// rdfDocument is an RSS 1.0 document, unlike RSS 2.0 the items are
+// siblings of the channel instead of its children
+type rdfDocument struct {
+	Channel rdfChannel `xml:"channel"`
+	Items   []rdfItem  `xml:"item"`
+}
+
+type rdfChannel struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+}
+
+type rdfItem struct {
+	About       string   `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Subjects    []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+}
+
+func parseRDF(data []byte) (*Feed, error) {
+	var doc rdfDocument
+	if err := newDecoder(data).Decode(&doc); err != nil {
+		return nil, err
+	}
+	feed := &Feed{
+		Title:       strings.TrimSpace(doc.Channel.Title),
+		Link:        strings.TrimSpace(doc.Channel.Link),
+		Description: strings.TrimSpace(doc.Channel.Description),
+	}
+	for _, item := range doc.Items {
+		feed.Items = append(feed.Items, item.toPost())
+	}
+	return feed, nil
+}
+
+func (item rdfItem) toPost() models.Post {
+	post := models.Post{
+		GUID:        strings.TrimSpace(item.About),
+		Title:       strings.TrimSpace(item.Title),
+		Description: strings.TrimSpace(item.Description),
+		Content:     strings.TrimSpace(item.Content),
+		Link:        firstNonEmpty(item.Link, item.About),
+		Author:      strings.TrimSpace(item.Creator),
+	}
+	if published, ok := parseDate(item.Date); ok {
+		post.PublishedAt = published
+	}
+	for _, subject := range item.Subjects {
+		if subject = strings.TrimSpace(subject); subject != "" {
+			post.Categories = append(post.Categories, subject)
+		}
+	}
+	return post
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+func TestParseRDF(t *testing.T) {
+	runFeedTests(t, []feedTest{
+		{
+			name: "rss 1.0",
+			doc: `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+	<channel rdf:about="https://example.com/rss">
+		<title>Example</title>
+		<link>https://example.com/</link>
+		<description>RDF site summary</description>
+		<items><rdf:Seq><rdf:li rdf:resource="https://example.com/1"/></rdf:Seq></items>
+	</channel>
+	<item rdf:about="https://example.com/1">
+		<title>First</title>
+		<link>https://example.com/1?from=rss</link>
+		<description>Summary</description>
+		<content:encoded><![CDATA[<p>Full</p>]]></content:encoded>
+		<dc:date>2004-05-06T07:08:09-05:00</dc:date>
+		<dc:creator>Jane</dc:creator>
+		<dc:subject>go</dc:subject>
+		<dc:subject>rdf</dc:subject>
+	</item>
+	<item rdf:about="https://example.com/2">
+		<title>No link</title>
+		<dc:date>2004-05-07</dc:date>
+	</item>
+</rdf:RDF>`,
+			want: Feed{
+				Title:       "Example",
+				Link:        "https://example.com/",
+				Description: "RDF site summary",
+				Items: []models.Post{
+					{
+						GUID:        "https://example.com/1",
+						Title:       "First",
+						Description: "Summary",
+						Content:     "<p>Full</p>",
+						Link:        "https://example.com/1?from=rss",
+						Author:      "Jane",
+						Categories:  []string{"go", "rdf"},
+						PublishedAt: date(2004, 5, 6, 12, 8, 9),
+					},
+					{
+						GUID:        "https://example.com/2",
+						Title:       "No link",
+						Link:        "https://example.com/2",
+						PublishedAt: date(2004, 5, 7, 0, 0, 0),
+					},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+type rssDocument struct {
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       []rssElement `xml:"title"`
+	Links       []rssElement `xml:"link"`
+	Description []rssElement `xml:"description"`
+	Items       []rssItem    `xml:"item"`
+}
+
+// rssElement is an element whose name is also used by the extensions many
+// RSS feeds embed (atom:link, media:title, itunes:author...), encoding/xml
+// matches them too so only the ones without namespace are the RSS values
+type rssElement struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       []rssElement  `xml:"title"`
+	Links       []rssElement  `xml:"link"`
+	Description []rssElement  `xml:"description"`
+	Content     string        `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Date        string        `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author      []rssElement  `xml:"author"`
+	Creator     string        `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []rssElement  `xml:"category"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+func parseRSS(data []byte) (*Feed, error) {
+	var doc rssDocument
+	if err := newDecoder(data).Decode(&doc); err != nil {
+		return nil, err
+	}
+	feed := &Feed{
+		Title:       rssValue(doc.Channel.Title),
+		Link:        rssValue(doc.Channel.Links),
+		Description: rssValue(doc.Channel.Description),
+	}
+	for _, item := range doc.Channel.Items {
+		feed.Items = append(feed.Items, item.toPost())
+	}
+	return feed, nil
+}
+
+func (item rssItem) toPost() models.Post {
+	post := models.Post{
+		GUID:        strings.TrimSpace(item.GUID.Value),
+		Title:       rssValue(item.Title),
+		Description: rssValue(item.Description),
+		Content:     strings.TrimSpace(item.Content),
+		Link:        rssValue(item.Links),
+		Author:      firstNonEmpty(item.Creator, rssValue(item.Author)),
+	}
+	// a permalink guid is the item link when no <link> is given
+	if post.Link == "" && post.GUID != "" && !strings.EqualFold(item.GUID.IsPermaLink, "false") &&
+		(strings.HasPrefix(post.GUID, "http://") || strings.HasPrefix(post.GUID, "https://")) {
+		post.Link = post.GUID
+	}
+	if published, ok := parseDate(firstNonEmpty(item.PubDate, item.Date)); ok {
+		post.PublishedAt = published
+	}
+	for _, category := range item.Categories {
+		if value := strings.TrimSpace(category.Value); category.XMLName.Space == "" && value != "" {
+			post.Categories = append(post.Categories, value)
+		}
+	}
+	if item.Enclosure != nil && item.Enclosure.URL != "" {
+		length, _ := strconv.ParseInt(strings.TrimSpace(item.Enclosure.Length), 10, 64)
+		post.Enclosure = &models.Enclosure{
+			URL:    strings.TrimSpace(item.Enclosure.URL),
+			Type:   item.Enclosure.Type,
+			Length: length,
+		}
+	}
+	return post
+}
+
+// rssValue returns the first non empty element without namespace
+func rssValue(elements []rssElement) string {
+	for _, element := range elements {
+		if element.XMLName.Space == "" {
+			if value := strings.TrimSpace(element.Value); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
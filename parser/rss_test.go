@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+func TestParseRSS(t *testing.T) {
+	runFeedTests(t, []feedTest{
+		{
+			name: "rss 2.0",
+			doc: `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel>
+	<title> Example </title>
+	<atom:link href="https://example.com/feed.xml" rel="self" type="application/rss+xml"/>
+	<link>https://example.com/</link>
+	<description>All the news</description>
+	<item>
+		<title>First &amp; foremost</title>
+		<link>https://example.com/1</link>
+		<description><![CDATA[<p>Summary</p>]]></description>
+		<content:encoded><![CDATA[<p>Full text</p>]]></content:encoded>
+		<guid isPermaLink="false">post-1</guid>
+		<pubDate>Tue, 10 Jun 2003 04:00:00 GMT</pubDate>
+		<dc:creator>Jane</dc:creator>
+		<author>jane@example.com (Jane Doe)</author>
+		<category>news</category>
+		<category> </category>
+		<category>go</category>
+		<enclosure url="https://example.com/1.mp3" type="audio/mpeg" length="1234"/>
+	</item>
+	<item>
+		<title>Permalink guid</title>
+		<guid>https://example.com/2</guid>
+		<dc:date>2003-06-11T08:30:00+02:00</dc:date>
+		<author>john@example.com</author>
+	</item>
+</channel>
+</rss>`,
+			want: Feed{
+				Title:       "Example",
+				Link:        "https://example.com/",
+				Description: "All the news",
+				Items: []models.Post{
+					{
+						GUID:        "post-1",
+						Title:       "First & foremost",
+						Description: "<p>Summary</p>",
+						Content:     "<p>Full text</p>",
+						Link:        "https://example.com/1",
+						Author:      "Jane",
+						Categories:  []string{"news", "go"},
+						Enclosure:   &models.Enclosure{URL: "https://example.com/1.mp3", Type: "audio/mpeg", Length: 1234},
+						PublishedAt: date(2003, 6, 10, 4, 0, 0),
+					},
+					{
+						GUID:        "https://example.com/2",
+						Title:       "Permalink guid",
+						Link:        "https://example.com/2",
+						Author:      "john@example.com",
+						PublishedAt: date(2003, 6, 11, 6, 30, 0),
+					},
+				},
+			},
+		},
+		{
+			name: "windows-1252",
+			doc: "<?xml version=\"1.0\" encoding=\"windows-1252\"?>\n" +
+				"<rss version=\"2.0\"><channel><title>Caf\xe9</title><link>https://example.com/</link>" +
+				"<item><title>\x93Quoted\x94 \x96 \x80 5</title><link>https://example.com/1</link>" +
+				"<description>Na\xefve\x85</description></item></channel></rss>",
+			want: Feed{
+				Title: "Café",
+				Link:  "https://example.com/",
+				Items: []models.Post{{
+					Title:       "“Quoted” – € 5",
+					Description: "Naïve…",
+					Link:        "https://example.com/1",
+				}},
+			},
+		},
+		{
+			name: "iso-8859-1",
+			doc: "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+				"<rss version=\"2.0\"><channel><title>Gr\xfc\xdfe</title>" +
+				"<item><title>\xbfQu\xe9?</title></item></channel></rss>",
+			want: Feed{
+				Title: "Grüße",
+				Items: []models.Post{{Title: "¿Qué?"}},
+			},
+		},
+		{
+			// the extension elements share their local name with RSS
+			// elements and come after them
+			name: "media rss",
+			doc: `<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/">
+<channel>
+	<title>Videos</title>
+	<media:title>Media channel</media:title>
+	<item>
+		<title>Item title</title>
+		<media:title>Media</media:title>
+		<description>Item description</description>
+		<media:description>MD</media:description>
+		<category>video</category>
+		<media:category>Arts/Movies</media:category>
+		<link>https://example.com/video</link>
+		<media:content url="https://example.com/video.mp4" type="video/mp4"/>
+	</item>
+</channel>
+</rss>`,
+			want: Feed{
+				Title: "Videos",
+				Items: []models.Post{{
+					Title:       "Item title",
+					Description: "Item description",
+					Link:        "https://example.com/video",
+					Categories:  []string{"video"},
+				}},
+			},
+		},
+		{
+			name: "itunes",
+			doc: `<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+<channel>
+	<title>Podcast</title>
+	<description>About the podcast</description>
+	<itunes:title>iTunes title</itunes:title>
+	<itunes:author>iTunes author</itunes:author>
+	<itunes:category text="Technology"/>
+	<item>
+		<title>Episode 1</title>
+		<itunes:title>Episode title</itunes:title>
+		<author>host@example.com</author>
+		<itunes:author>The host</itunes:author>
+		<description>Show notes</description>
+		<itunes:summary>Summary</itunes:summary>
+		<enclosure url="https://example.com/1.mp3" type="audio/mpeg" length="42"/>
+	</item>
+	<item>
+		<itunes:title>Only an iTunes title</itunes:title>
+		<itunes:author>Only an iTunes author</itunes:author>
+	</item>
+</channel>
+</rss>`,
+			want: Feed{
+				Title:       "Podcast",
+				Description: "About the podcast",
+				Items: []models.Post{
+					{
+						Title:       "Episode 1",
+						Author:      "host@example.com",
+						Description: "Show notes",
+						Enclosure:   &models.Enclosure{URL: "https://example.com/1.mp3", Type: "audio/mpeg", Length: 42},
+					},
+					{},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+// maxFeedSize caps the size of a downloaded feed document (10 MB)
+const maxFeedSize = 10 << 20
+
+// FetchResult is the outcome of a conditional feed download
+type FetchResult struct {
+	StatusCode   int
+	NotModified  bool // the server answered 304, Body is empty
+	Body         []byte
+	ETag         string // validators to send on the next fetch
+	LastModified string
+}
+
+// Fetcher downloads feeds with conditional requests so unchanged
+// feeds are not downloaded again
+type Fetcher struct {
+	client *http.Client
+}
+
+func NewFetcher(timeout time.Duration) *Fetcher {
+	return &Fetcher{client: &http.Client{Timeout: timeout}}
+}
+
+// Fetch downloads a feed, sending the validators stored on the feed by the previous fetch
+func (f *Fetcher) Fetch(ctx context.Context, feed models.Feed) (*FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "RSS-Aggregator/1.0")
+	if feed.ETag != "" {
+		req.Header.Set("If-None-Match", feed.ETag)
+	}
+	if feed.LastModified != "" {
+		req.Header.Set("If-Modified-Since", feed.LastModified)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &FetchResult{
+		StatusCode:   resp.StatusCode,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		result.Body, err = io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+		if err != nil {
+			return nil, err
+		}
+	case http.StatusNotModified:
+		result.NotModified = true
+		// a 304 may omit the validators, the previous ones are still valid
+		if result.ETag == "" {
+			result.ETag = feed.ETag
+		}
+		if result.LastModified == "" {
+			result.LastModified = feed.LastModified
+		}
+	default:
+		return result, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package scraper
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/config"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/parser"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+)
+
+type Scraper struct {
+	config  *config.ScraperConfig
+	feeds   storage.FeedStore
+	posts   storage.PostStore
+	fetcher *Fetcher
+}
+
+func NewScraper(config *config.ScraperConfig, feeds storage.FeedStore, posts storage.PostStore) *Scraper {
+	return &Scraper{
+		config:  config,
+		feeds:   feeds,
+		posts:   posts,
+		fetcher: NewFetcher(config.FetchTimeout),
+	}
+}
+
+// Run scrapes the feeds every interval until ctx is cancelled,
+// it only returns once all the running fetches are done
+func (s *Scraper) Run(ctx context.Context) {
+	log.Printf("📰 Scraper started: %d workers, %d feeds every %s", s.config.Concurrency, s.config.BatchSize, s.config.Interval)
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.scrapeOnce(ctx)
+		select {
+		case <-ctx.Done():
+			log.Println("📰 Scraper stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scraper) scrapeOnce(ctx context.Context) {
+	feeds, err := s.feeds.NextFeedsToFetch(ctx, s.config.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("📰 Scraper: failed to get feeds: %v", err)
+		}
+		return
+	}
+
+	// bounded worker pool: sem holds one slot per running fetch
+	sem := make(chan struct{}, s.config.Concurrency)
+	var wg sync.WaitGroup
+	for _, feed := range feeds {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func(feed models.Feed) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				s.scrapeFeed(ctx, feed)
+			}(feed)
+		}
+	}
+	wg.Wait()
+}
+
+func (s *Scraper) scrapeFeed(ctx context.Context, feed models.Feed) {
+	result, err := s.fetcher.Fetch(ctx, feed)
+	if err == nil && !result.NotModified {
+		err = s.savePosts(ctx, &feed, result.Body)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		status := 0
+		if result != nil {
+			status = result.StatusCode
+		}
+		log.Printf("📰 Scraper: feed %s failed: %v", feed.URL, err)
+		if err := s.feeds.MarkFeedFailed(ctx, feed.ID, status); err != nil {
+			log.Printf("📰 Scraper: failed to update feed %s: %v", feed.URL, err)
+		}
+		return
+	}
+	if err := s.feeds.MarkFeedFetched(ctx, feed.ID, result.StatusCode, result.ETag, result.LastModified); err != nil {
+		log.Printf("📰 Scraper: failed to update feed %s: %v", feed.URL, err)
+	}
+}
+
+func (s *Scraper) savePosts(ctx context.Context, feed *models.Feed, data []byte) error {
+	parsed, err := parser.Parse(data)
+	if err != nil {
+		return err
+	}
+	if feed.Title == "" || feed.SiteLink == "" {
+		if err := s.feeds.FillFeedInfo(ctx, feed.ID, parsed.Title, parsed.Link); err != nil {
+			return err
+		}
+	}
+	inserted, updated := 0, 0
+	for _, post := range parsed.Items {
+		// titles are optional in JSON Feed, a link is what makes an item usable
+		if post.Link == "" {
+			continue
+		}
+		post.FeedID = feed.ID
+		isNew, isUpdated, err := s.posts.UpsertFeedPost(ctx, post)
+		if err != nil {
+			return err
+		}
+		if isNew {
+			inserted++
+		} else if isUpdated {
+			updated++
+		}
+	}
+	if inserted > 0 || updated > 0 {
+		log.Printf("📰 Scraper: %d new and %d updated posts from %s", inserted, updated, feed.URL)
+	}
+	return nil
+}
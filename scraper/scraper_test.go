@@ -0,0 +1,259 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/config"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/memory"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// feedServer serves a feed document, with validators when etag is set, and
+// records the conditional headers of the requests
+type feedServer struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	doc          string
+	etag         string
+	lastModified string
+	requests     []http.Header
+}
+
+func newFeedServer(t *testing.T, doc string) *feedServer {
+	s := &feedServer{doc: doc}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, r.Header.Clone())
+		if s.etag != "" && r.Header.Get("If-None-Match") == s.etag {
+			// a 304 does not have to repeat the validators
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if s.etag != "" {
+			w.Header().Set("ETag", s.etag)
+		}
+		if s.lastModified != "" {
+			w.Header().Set("Last-Modified", s.lastModified)
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(s.doc))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *feedServer) set(doc, etag, lastModified string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.doc, s.etag, s.lastModified = doc, etag, lastModified
+}
+
+func (s *feedServer) lastRequest() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[len(s.requests)-1]
+}
+
+type scraperTest struct {
+	scraper *Scraper
+	store   *memory.Store
+	feed    models.Feed
+	userID  bson.ObjectID
+}
+
+func newScraperTest(t *testing.T, url string) *scraperTest {
+	store := memory.New()
+	userID := bson.NewObjectID()
+	feed := models.Feed{URL: url, UserID: userID, CreatedAt: time.Now()}
+	if err := store.CreateFeed(context.Background(), &feed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.FollowFeed(context.Background(), userID, feed.ID); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.ScraperConfig{Concurrency: 2, BatchSize: 10, Interval: time.Hour, FetchTimeout: 5 * time.Second}
+	return &scraperTest{scraper: NewScraper(cfg, store, store), store: store, feed: feed, userID: userID}
+}
+
+// scrape runs a scraping round and returns the feed as it was saved
+func (st *scraperTest) scrape(t *testing.T) models.Feed {
+	t.Helper()
+	st.scraper.scrapeOnce(context.Background())
+	feed, err := st.store.GetFeed(context.Background(), st.feed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *feed
+}
+
+// posts returns the posts of the feed by link
+func (st *scraperTest) posts(t *testing.T) map[string]models.Post {
+	t.Helper()
+	list, err := st.store.ListPosts(context.Background(), st.userID, storage.PostListQuery{
+		PostFilter: storage.PostFilter{FeedID: &st.feed.ID},
+		Limit:      100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byLink := make(map[string]models.Post, len(list))
+	for _, post := range list {
+		if _, ok := byLink[post.Link]; ok {
+			t.Errorf("post %s saved twice", post.Link)
+		}
+		byLink[post.Link] = post
+	}
+	return byLink
+}
+
+const testFeed = `<rss version="2.0"><channel><title>Test</title><link>https://example.com/</link>
+<item><title>First</title><link>https://example.com/1</link><guid>1</guid><description>One</description>
+<pubDate>Mon, 01 Jan 2024 10:00:00 GMT</pubDate></item>
+<item><title>Second</title><link>https://example.com/2</link><description>Two</description>
+<pubDate>Tue, 02 Jan 2024 10:00:00 GMT</pubDate></item>
+</channel></rss>`
+
+func TestScrapeConditionalFetch(t *testing.T) {
+	server := newFeedServer(t, testFeed)
+	lastModified := "Tue, 02 Jan 2024 10:00:00 GMT"
+	server.set(testFeed, `"v1"`, lastModified)
+	st := newScraperTest(t, server.URL)
+
+	feed := st.scrape(t)
+	if header := server.lastRequest(); header.Get("If-None-Match") != "" || header.Get("If-Modified-Since") != "" {
+		t.Errorf("first fetch sent validators %v", header)
+	}
+	if feed.ETag != `"v1"` || feed.LastModified != lastModified || feed.LastStatus != http.StatusOK {
+		t.Errorf("feed after a 200: etag %q, last modified %q, status %d", feed.ETag, feed.LastModified, feed.LastStatus)
+	}
+	if feed.Title != "Test" || feed.SiteLink != "https://example.com/" {
+		t.Errorf("feed info = %q %q, want the ones of the document", feed.Title, feed.SiteLink)
+	}
+	before := st.posts(t)
+	if len(before) != 2 {
+		t.Fatalf("%d posts after the first fetch, want 2", len(before))
+	}
+	fetchedAt := *feed.LastFetchedAt
+
+	time.Sleep(10 * time.Millisecond)
+	feed = st.scrape(t)
+	if header := server.lastRequest(); header.Get("If-None-Match") != `"v1"` || header.Get("If-Modified-Since") != lastModified {
+		t.Errorf("second fetch sent If-None-Match %q and If-Modified-Since %q",
+			header.Get("If-None-Match"), header.Get("If-Modified-Since"))
+	}
+	if feed.LastStatus != http.StatusNotModified || !feed.LastFetchedAt.After(fetchedAt) {
+		t.Errorf("feed after a 304: status %d, fetched at %v, want 304 after %v", feed.LastStatus, feed.LastFetchedAt, fetchedAt)
+	}
+	// the 304 had no validators, the stored ones are still the right ones
+	if feed.ETag != `"v1"` || feed.LastModified != lastModified {
+		t.Errorf("validators after a 304: %q %q", feed.ETag, feed.LastModified)
+	}
+	after := st.posts(t)
+	for link, post := range before {
+		if got, ok := after[link]; !ok || got.ID != post.ID || !got.UpdatedAt.Equal(post.UpdatedAt) {
+			t.Errorf("post %s changed after a 304", link)
+		}
+	}
+
+	// a new version of the feed is downloaded again
+	server.set(testFeed, `"v2"`, "")
+	feed = st.scrape(t)
+	if feed.ETag != `"v2"` || feed.LastModified != "" || feed.LastStatus != http.StatusOK {
+		t.Errorf("feed after a new version: etag %q, last modified %q, status %d", feed.ETag, feed.LastModified, feed.LastStatus)
+	}
+}
+
+func TestScrapeFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer server.Close()
+	st := newScraperTest(t, server.URL)
+	st.scrape(t)
+	feed := st.scrape(t)
+	if feed.LastStatus != http.StatusGone || feed.ErrorCount != 2 || feed.LastFetchedAt == nil {
+		t.Errorf("failed feed: status %d, %d errors, fetched at %v", feed.LastStatus, feed.ErrorCount, feed.LastFetchedAt)
+	}
+}
+
+func TestScrapeDeduplicatesPosts(t *testing.T) {
+	// without validators the whole feed is downloaded every time
+	server := newFeedServer(t, testFeed)
+	st := newScraperTest(t, server.URL)
+
+	st.scrape(t)
+	first := st.posts(t)
+	if len(first) != 2 {
+		t.Fatalf("%d posts after the first fetch, want 2", len(first))
+	}
+	time.Sleep(10 * time.Millisecond)
+	st.scrape(t)
+	unchanged := st.posts(t)
+	if len(unchanged) != 2 {
+		t.Errorf("%d posts after fetching the same feed again, want 2", len(unchanged))
+	}
+	for link, post := range first {
+		if got := unchanged[link]; got.ID != post.ID || !got.UpdatedAt.Equal(post.UpdatedAt) {
+			t.Errorf("unchanged post %s was written again", link)
+		}
+	}
+
+	// the first item is found by its guid and the second by its link and
+	// title, both are updated in place
+	edited := strings.ReplaceAll(testFeed, "<description>One</description>", "<description>One, edited</description>")
+	edited = strings.ReplaceAll(edited, "<description>Two</description>", "<description>Two, edited</description>")
+	server.set(edited, "", "")
+	st.scrape(t)
+	updated := st.posts(t)
+	if len(updated) != 2 {
+		t.Fatalf("%d posts after the items were edited, want 2", len(updated))
+	}
+	for link, post := range first {
+		got := updated[link]
+		if got.ID != post.ID || !got.CreatedAt.Equal(post.CreatedAt) {
+			t.Errorf("edited post %s was not updated in place", link)
+		}
+		if got.ContentHash == post.ContentHash || !strings.HasSuffix(got.Description, ", edited") || !got.UpdatedAt.After(post.UpdatedAt) {
+			t.Errorf("edited post %s: description %q, content hash changed %v", link, got.Description, got.ContentHash != post.ContentHash)
+		}
+	}
+}
+
+func TestUpsertFeedPost(t *testing.T) {
+	store := memory.New()
+	ctx := context.Background()
+	feedID := bson.NewObjectID()
+	post := models.Post{FeedID: feedID, GUID: "1", Title: "Title", Link: "https://example.com/1"}
+
+	steps := []struct {
+		name              string
+		post              models.Post
+		inserted, updated bool
+	}{
+		{"new item", post, true, false},
+		{"same item", post, false, false},
+		{"edited item", models.Post{FeedID: feedID, GUID: "1", Title: "Edited", Link: "https://example.com/1"}, false, true},
+		{"same guid in another feed", models.Post{FeedID: bson.NewObjectID(), GUID: "1", Title: "Title"}, true, false},
+		{"item without guid", models.Post{FeedID: feedID, Title: "Title", Link: "https://example.com/2"}, true, false},
+		{"same link and title", models.Post{FeedID: feedID, Title: "Title", Link: "https://example.com/2", Author: "Jane"}, false, true},
+	}
+	for _, step := range steps {
+		inserted, updated, err := store.UpsertFeedPost(ctx, step.post)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if inserted != step.inserted || updated != step.updated {
+			t.Errorf("%s: inserted %v updated %v, want %v %v", step.name, inserted, updated, step.inserted, step.updated)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// APIKeyPrefix starts every API key, it tells them apart from JWTs in a
+// Bearer header
+const APIKeyPrefix = "rss_"
+
+const (
+	// apiKeyShownLength is the length of the start of a key kept in clear to
+	// recognize it in a list
+	apiKeyShownLength = len(APIKeyPrefix) + 8
+	// apiKeyTouchInterval limits how often requests update the last use of a key
+	apiKeyTouchInterval = time.Minute
+)
+
+// ErrInvalidAPIKey is returned for keys that are unknown, revoked or expired
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyService creates the API keys of the users and authenticates requests with them
+type APIKeyService struct {
+	keys  storage.APIKeyStore
+	users storage.UserStore
+}
+
+func NewAPIKeyService(keys storage.APIKeyStore, users storage.UserStore) *APIKeyService {
+	return &APIKeyService{keys: keys, users: users}
+}
+
+// Create returns a new key of the user with its record, the key itself is
+// not stored and can not be shown again. The key can only get the scopes of
+// the token that creates it, no scopes means all of them.
+func (s *APIKeyService) Create(ctx context.Context, creator *models.AccessTokenClaims, name string, requested []string, expiresAt *time.Time) (string, *models.APIKey, error) {
+	scopes, err := grantScopes(creator.Scopes, requested)
+	if err != nil {
+		return "", nil, err
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	record := &models.APIKey{
+		UserID:    creator.UserID,
+		Name:      name,
+		Prefix:    key[:apiKeyShownLength],
+		KeyHash:   hashSecretToken(key),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	if err := s.keys.CreateAPIKey(ctx, record); err != nil {
+		return "", nil, err
+	}
+	return key, record, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, userID bson.ObjectID) ([]models.APIKey, error) {
+	return s.keys.ListAPIKeys(ctx, userID)
+}
+
+// Revoke returns storage.ErrNotFound when the user has no such key
+func (s *APIKeyService) Revoke(ctx context.Context, userID, id bson.ObjectID) error {
+	return s.keys.RevokeAPIKey(ctx, userID, id)
+}
+
+// Authenticate returns the claims of the user a key belongs to, and records
+// the use of the key. The scopes of the key are limited to the ones the
+// current roles of the user allow.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*models.AccessTokenClaims, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	record, err := s.keys.GetAPIKeyByHash(ctx, hashSecretToken(key))
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !record.Active(now) {
+		return nil, ErrInvalidAPIKey
+	}
+	user, err := s.users.GetUserByID(ctx, record.UserID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.keys.TouchAPIKey(ctx, record.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	return &models.AccessTokenClaims{
+		UserID:   user.ID,
+		Email:    user.Email,
+		Roles:    user.UserRoles(),
+		Scopes:   limitScopes(record.Scopes, models.ScopesForRoles(user.UserRoles())),
+		APIKeyID: record.ID,
+	}, nil
+}
@@ -6,46 +6,47 @@ import (
 	"time"
 
 	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
 	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
-	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func RegisterUser(col *mongo.Collection, username, email, password string) error {
+// RegisterUser creates the user, it returns a nil user when the email or the
+// username is already taken
+func RegisterUser(users storage.UserStore, username, email, password string) (*models.Auth, error) {
 	ctx := context.Background()
-	countMail, _ := col.CountDocuments(ctx, bson.M{"email": email})
-	if countMail > 0 {
+	if _, err := users.GetUserByEmail(ctx, email); err == nil {
 		log.Println("Email already exists")
-		return nil
+		return nil, nil
 	}
-	countUser, _ := col.CountDocuments(ctx, bson.M{"username": username})
-	if countUser > 0 {
+	if _, err := users.GetUserByUsername(ctx, username); err == nil {
 		log.Println("Username already exists")
-		return nil
+		return nil, nil
 	}
 	hashedPassword, err := utils.HashPassword(password)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	newUser := models.Auth{
 		Username:  username,
 		Email:     email,
 		Password:  hashedPassword,
+		Roles:     []string{models.RoleUser},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	_, err = col.InsertOne(ctx, newUser)
-	return err
+	if err := users.CreateUser(ctx, &newUser); err != nil {
+		return nil, err
+	}
+	return &newUser, nil
 }
 
-func LoginUser(col *mongo.Collection, email, password string) (*models.Auth, error) {
+func LoginUser(users storage.UserStore, email, password string) (*models.Auth, error) {
 	// find the user by email
 	ctx := context.Background()
-	var user models.Auth
 	log.Println("Trying to find user with email:", email)
 	log.Printf("Auth struct type: %#v\n", models.Auth{})
 
-	err := col.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	user, err := users.GetUserByEmail(ctx, email)
 	if err != nil {
 		log.Println("User not found :", err)
 		return nil, err
@@ -56,5 +57,5 @@ func LoginUser(col *mongo.Collection, email, password string) (*models.Auth, err
 		log.Println("Invalid password")
 		return nil, valid
 	}
-	return &user, nil
+	return user, nil
 }
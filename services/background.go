@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// backgroundTimeout bounds the work started by inBackground
+const backgroundTimeout = time.Minute
+
+// inBackground runs work off the request path with its own context, like
+// mailing a link only to registered emails: waiting for the mail server would
+// tell who is registered by the response time. Errors are only logged.
+func inBackground(what string, work func(ctx context.Context) error) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+		defer cancel()
+		if err := work(ctx); err != nil {
+			log.Printf("Error %s: %v", what, err)
+		}
+	}()
+}
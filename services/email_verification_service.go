@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/config"
+	"github.com/Aym-Aymen777/RSS-Aggregator/mailer"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+)
+
+// ErrInvalidVerificationToken is returned for verification tokens that are unknown, used or expired
+var ErrInvalidVerificationToken = errors.New("invalid email verification token")
+
+// EmailVerificationService mails verification links to the users and applies
+// the policy for the users who have not followed theirs yet
+type EmailVerificationService struct {
+	config        *config.EmailVerificationConfig
+	users         storage.UserStore
+	verifications storage.EmailVerificationStore
+	mailer        mailer.Mailer
+}
+
+func NewEmailVerificationService(config *config.EmailVerificationConfig, users storage.UserStore, verifications storage.EmailVerificationStore, mailer mailer.Mailer) *EmailVerificationService {
+	return &EmailVerificationService{config: config, users: users, verifications: verifications, mailer: mailer}
+}
+
+// SendVerification mails a new verification link to the user
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *models.Auth) error {
+	token := newSecretToken()
+	verification := &models.EmailVerification{
+		ID:        hashSecretToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.config.Expiry),
+		CreatedAt: time.Now(),
+	}
+	if err := s.verifications.CreateEmailVerification(ctx, verification); err != nil {
+		return err
+	}
+	link := s.config.URL + "?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen the link below to verify your email, it expires in %s:\n\n%s\n\n"+
+			"If you did not create an account you can ignore this email.\n", user.Username, s.config.Expiry, link),
+	})
+}
+
+// QueueVerification mails a new verification link to the user in the
+// background, registering an email that is taken must not take less time
+func (s *EmailVerificationService) QueueVerification(user *models.Auth) {
+	inBackground("sending email verification", func(ctx context.Context) error {
+		return s.SendVerification(ctx, user)
+	})
+}
+
+// ResendVerification mails a new link to the user with the email. An unknown
+// or already verified email is not an error, callers must not tell who is
+// registered, and the link is mailed with QueueVerification.
+func (s *EmailVerificationService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.users.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			log.Printf("Email verification requested for unknown email %s", email)
+			return nil
+		}
+		return err
+	}
+	if !user.EmailVerified {
+		s.QueueVerification(user)
+	}
+	return nil
+}
+
+// VerifyEmail marks the email of the user the token was mailed to as verified
+func (s *EmailVerificationService) VerifyEmail(ctx context.Context, token string) error {
+	verification, err := s.verifications.ConsumeEmailVerification(ctx, hashSecretToken(token))
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+	return s.users.MarkEmailVerified(ctx, verification.UserID)
+}
+
+// BlocksLogin reports whether the policy keeps the user from logging in
+func (s *EmailVerificationService) BlocksLogin(user *models.Auth) bool {
+	return s.config.Policy == config.VerificationPolicyLogin && !user.EmailVerified
+}
+
+// BlocksWrites reports whether the policy keeps unverified users from writing
+func (s *EmailVerificationService) BlocksWrites() bool {
+	return s.config.Policy == config.VerificationPolicyWrites
+}
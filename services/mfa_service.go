@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/config"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/totp"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrMFANotEnrolled is returned when confirming without a pending enrollment
+	ErrMFANotEnrolled = errors.New("two-factor authentication enrollment not started")
+	ErrMFANotEnabled  = errors.New("two-factor authentication is not enabled")
+	// ErrInvalidMFACode is returned for wrong, reused or expired codes
+	ErrInvalidMFACode = errors.New("invalid two-factor code")
+	// ErrMFALocked is returned while too many wrong codes were given in a row
+	ErrMFALocked       = errors.New("too many invalid two-factor codes")
+	ErrInvalidPassword = errors.New("invalid password")
+)
+
+const (
+	recoveryCodeCount = 10
+	// recoveryCodeAlphabet leaves out the characters that are easily confused
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// MFAService manages the TOTP second factor of the users and checks their codes
+type MFAService struct {
+	config *config.MFAConfig
+	users  storage.UserStore
+	mfa    storage.MFAStore
+}
+
+func NewMFAService(config *config.MFAConfig, users storage.UserStore, mfa storage.MFAStore) *MFAService {
+	return &MFAService{config: config, users: users, mfa: mfa}
+}
+
+// Enroll starts the enrollment of the user with a new secret and returns it
+// with its provisioning URI, a pending enrollment is replaced
+func (s *MFAService) Enroll(ctx context.Context, userID bson.ObjectID, email string) (string, string, error) {
+	existing, err := s.mfa.GetMFA(ctx, userID)
+	if err != nil && err != storage.ErrNotFound {
+		return "", "", err
+	}
+	if existing != nil && existing.Enabled {
+		return "", "", ErrMFAAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	mfa := &models.MFA{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+	if err := s.mfa.SaveMFA(ctx, mfa); err != nil {
+		return "", "", err
+	}
+	return secret, totp.URI(s.config.Issuer, email, secret), nil
+}
+
+// Confirm enables the pending second factor of the user with a first code
+// and returns the recovery codes, they are not stored in clear and can not be
+// shown again
+func (s *MFAService) Confirm(ctx context.Context, userID bson.ObjectID, code string) ([]string, error) {
+	mfa, err := s.mfa.GetMFA(ctx, userID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	if mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	now := time.Now()
+	step, ok := totp.Validate(mfa.Secret, code, now)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	mfa.Enabled = true
+	mfa.EnabledAt = &now
+	mfa.LastStep = step
+	mfa.RecoveryCodes = hashes
+	if err := s.mfa.SaveMFA(ctx, mfa); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Enabled reports whether logins of the user need a second factor
+func (s *MFAService) Enabled(ctx context.Context, userID bson.ObjectID) (bool, error) {
+	mfa, err := s.mfa.GetMFA(ctx, userID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return mfa.Enabled, nil
+}
+
+// Verify checks a TOTP code or a recovery code of the user, each code is only
+// accepted once. Wrong codes lock the second factor for a while after
+// MaxAttempts of them in a row.
+func (s *MFAService) Verify(ctx context.Context, userID bson.ObjectID, code string) error {
+	mfa, err := s.mfa.GetMFA(ctx, userID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return ErrMFANotEnabled
+		}
+		return err
+	}
+	if !mfa.Enabled {
+		return ErrMFANotEnabled
+	}
+	now := time.Now()
+	if mfa.FailedAttempts >= s.config.MaxAttempts && mfa.LastFailureAt != nil && now.Sub(*mfa.LastFailureAt) < s.config.Lockout {
+		return ErrMFALocked
+	}
+	if step, ok := totp.Validate(mfa.Secret, code, now); ok {
+		err = s.mfa.UseTOTPStep(ctx, userID, step)
+	} else {
+		err = s.mfa.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	}
+	if err != storage.ErrNotFound {
+		return err
+	}
+	if err := s.mfa.RecordMFAFailure(ctx, userID); err != nil {
+		return err
+	}
+	return ErrInvalidMFACode
+}
+
+// Disable removes the second factor of the user, who has to give their
+// password and a code again. Users provisioned through single sign-on have
+// no password and only give a code.
+func (s *MFAService) Disable(ctx context.Context, userID bson.ObjectID, password, code string) error {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Password != "" && utils.CheckPassword(password, user.Password) != nil {
+		return ErrInvalidPassword
+	}
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.mfa.DeleteMFA(ctx, userID)
+}
+
+// newRecoveryCodes returns recovery codes like "abcde-fgh23" and their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	b := make([]byte, 10)
+	for i := range codes {
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		var code strings.Builder
+		for j, c := range b {
+			if j == 5 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(recoveryCodeAlphabet[int(c)%len(recoveryCodeAlphabet)])
+		}
+		codes[i] = code.String()
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores the case, spaces and dashes users may type differently
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
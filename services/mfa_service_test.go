@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/config"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/memory"
+	"github.com/Aym-Aymen777/RSS-Aggregator/totp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// enrolledMFA returns a service with a user whose second factor is enabled,
+// the secret of that factor and the step of the code that enabled it
+func enrolledMFA(t *testing.T) (*MFAService, bson.ObjectID, string, int64) {
+	t.Helper()
+	store := memory.New()
+	service := NewMFAService(&config.MFAConfig{Issuer: "test", MaxAttempts: 5, Lockout: time.Minute}, store, store)
+	userID := bson.NewObjectID()
+	ctx := context.Background()
+	secret, _, err := service.Enroll(ctx, userID, "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := totp.Step(time.Now()) - 1
+	if _, err := service.Confirm(ctx, userID, mustCode(t, secret, step)); err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	return service, userID, secret, step
+}
+
+func mustCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestMFAVerifyRejectsReplayedCodes(t *testing.T) {
+	service, userID, secret, step := enrolledMFA(t)
+	ctx := context.Background()
+
+	// the code given to confirm the enrollment is used already
+	if err := service.Verify(ctx, userID, mustCode(t, secret, step)); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("replay of the confirmation code: %v, want ErrInvalidMFACode", err)
+	}
+	code := mustCode(t, secret, step+1)
+	if err := service.Verify(ctx, userID, code); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := service.Verify(ctx, userID, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("replay of a login code: %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestMFAVerifyLocksAfterWrongCodes(t *testing.T) {
+	service, userID, secret, step := enrolledMFA(t)
+	ctx := context.Background()
+	for range service.config.MaxAttempts {
+		if err := service.Verify(ctx, userID, "000000x"); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("wrong code: %v, want ErrInvalidMFACode", err)
+		}
+	}
+	code := mustCode(t, secret, step+1)
+	if err := service.Verify(ctx, userID, code); !errors.Is(err, ErrMFALocked) {
+		t.Errorf("valid code while locked: %v, want ErrMFALocked", err)
+	}
+}
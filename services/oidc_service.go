@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/config"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/oidc"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+)
+
+var (
+	// ErrInvalidOIDCState is returned for callbacks that do not belong to a
+	// login started by the same browser, or whose login expired
+	ErrInvalidOIDCState = errors.New("invalid oidc state")
+	// ErrOIDCLoginFailed is returned when the provider did not vouch for the
+	// user, like for an invalid code or ID token
+	ErrOIDCLoginFailed = errors.New("oidc login failed")
+	// ErrOIDCEmailNotVerified is returned for provider users without a
+	// verified email, an account is only linked by a verified email
+	ErrOIDCEmailNotVerified = errors.New("oidc email not verified")
+	// ErrOIDCNoAccount is returned for provider users without an account
+	// when accounts are not provisioned
+	ErrOIDCNoAccount = errors.New("no account for oidc user")
+)
+
+// usernameAttempts is how many usernames are tried for a provisioned user
+// before giving up
+const usernameAttempts = 5
+
+// OIDCService logs users in through an OpenID Connect provider. The user is
+// found by their account at the provider, or linked by email the first time,
+// and gets the tokens of the service like for any other login.
+type OIDCService struct {
+	config       *config.OIDCConfig
+	provider     *oidc.Provider
+	users        storage.UserStore
+	identities   storage.IdentityStore
+	apiKeys      storage.APIKeyStore
+	mfa          storage.MFAStore
+	tokenService *TokenService
+}
+
+func NewOIDCService(config *config.OIDCConfig, provider *oidc.Provider, users storage.UserStore, identities storage.IdentityStore, apiKeys storage.APIKeyStore, mfa storage.MFAStore, tokenService *TokenService) *OIDCService {
+	return &OIDCService{config: config, provider: provider, users: users, identities: identities, apiKeys: apiKeys, mfa: mfa, tokenService: tokenService}
+}
+
+// Begin starts a login, it returns the URL of the provider to send the user to
+// and the state token the browser has to bring back to the callback
+func (s *OIDCService) Begin(ctx context.Context) (string, string, error) {
+	state := oidc.NewCodeVerifier()
+	nonce := oidc.NewCodeVerifier()
+	verifier := oidc.NewCodeVerifier()
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return "", "", err
+	}
+	stateToken, err := s.tokenService.GenerateOIDCState(state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, stateToken, nil
+}
+
+// Complete finishes the login of the callback and returns the user, the code
+// is exchanged with the verifier of the state token and the ID token must
+// carry its nonce
+func (s *OIDCService) Complete(ctx context.Context, stateToken, state, code string) (*models.Auth, error) {
+	flow, err := s.tokenService.ValidateOIDCState(stateToken)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(flow.State), []byte(state)) != 1 {
+		return nil, ErrInvalidOIDCState
+	}
+	token, err := s.provider.Exchange(ctx, code, flow.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC code exchange failed: %v", err)
+		return nil, ErrOIDCLoginFailed
+	}
+	claims, err := s.provider.VerifyIDToken(ctx, token.IDToken, flow.Nonce)
+	if err != nil {
+		log.Printf("OIDC ID token rejected: %v", err)
+		return nil, ErrOIDCLoginFailed
+	}
+	return s.findUser(ctx, claims)
+}
+
+// findUser returns the user linked to the provider account, linking it to
+// the user with the same email or a new user the first time
+func (s *OIDCService) findUser(ctx context.Context, claims *oidc.IDTokenClaims) (*models.Auth, error) {
+	identity, err := s.identities.GetIdentity(ctx, s.provider.Issuer(), claims.Subject)
+	if err == nil {
+		return s.users.GetUserByID(ctx, identity.UserID)
+	}
+	if err != storage.ErrNotFound {
+		return nil, err
+	}
+	// an unverified email could belong to anyone, linking by it would hand
+	// them the account of its owner
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+	user, err := s.users.GetUserByEmail(ctx, claims.Email)
+	switch {
+	case err == storage.ErrNotFound:
+		if !s.config.AutoProvision {
+			return nil, ErrOIDCNoAccount
+		}
+		if user, err = s.provisionUser(ctx, claims); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case !user.EmailVerified:
+		if err := s.reclaimAccount(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	err = s.identities.CreateIdentity(ctx, &models.Identity{
+		UserID:    user.ID,
+		Issuer:    s.provider.Issuer(),
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		CreatedAt: time.Now(),
+	})
+	// a concurrent login of the same account linked it first
+	if err != nil && err != storage.ErrDuplicate {
+		return nil, err
+	}
+	log.Printf("Linked OIDC account %s to user %s", claims.Subject, user.ID.Hex())
+	return user, nil
+}
+
+// reclaimAccount hands an account whose email was never verified to the owner
+// of the email the provider verified. Anyone could have registered it with
+// that email beforehand, so its password, sessions, API keys and second
+// factor are removed and only the provider can log in to it.
+func (s *OIDCService) reclaimAccount(ctx context.Context, user *models.Auth) error {
+	if err := s.users.UpdatePassword(ctx, user.ID, ""); err != nil {
+		return err
+	}
+	if err := s.tokenService.RevokeUserSessions(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := s.apiKeys.RevokeUserAPIKeys(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := s.mfa.DeleteMFA(ctx, user.ID); err != nil && err != storage.ErrNotFound {
+		return err
+	}
+	if err := s.users.MarkEmailVerified(ctx, user.ID); err != nil {
+		return err
+	}
+	log.Printf("Reclaimed unverified user %s for its OIDC account", user.ID.Hex())
+	now := time.Now()
+	user.Password = ""
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	return nil
+}
+
+// provisionUser creates the account of a provider user. It has no password,
+// the user logs in through the provider or sets one with a password reset.
+func (s *OIDCService) provisionUser(ctx context.Context, claims *oidc.IDTokenClaims) (*models.Auth, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	now := time.Now()
+	for attempt := 0; attempt < usernameAttempts; attempt++ {
+		username := base
+		if attempt > 0 {
+			suffix := make([]byte, 3)
+			rand.Read(suffix)
+			username = base + "-" + hex.EncodeToString(suffix)
+		}
+		if _, err := s.users.GetUserByUsername(ctx, username); err == nil {
+			continue
+		} else if err != storage.ErrNotFound {
+			return nil, err
+		}
+		user := models.Auth{
+			Username:        username,
+			Email:           claims.Email,
+			Roles:           []string{models.RoleUser},
+			EmailVerified:   true,
+			EmailVerifiedAt: &now,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		if err := s.users.CreateUser(ctx, &user); err != nil {
+			if err == storage.ErrDuplicate {
+				continue
+			}
+			return nil, err
+		}
+		log.Printf("Provisioned user %s for OIDC account %s", user.ID.Hex(), claims.Subject)
+		return &user, nil
+	}
+	return nil, errors.New("no free username for oidc user")
+}
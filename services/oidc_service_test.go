@@ -0,0 +1,399 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/config"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/oidc"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/memory"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "client"
+	testClientSecret = "secret"
+	testRedirectURL  = "http://localhost/v1/auth/oidc/callback"
+)
+
+// mockProvider is an OpenID Connect provider that logs in whoever it is told
+// to, the claims of its ID tokens can be changed by the tests
+type mockProvider struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu sync.Mutex
+	// claims of the next ID tokens, on top of the ones of the login
+	claims jwt.MapClaims
+	codes  map[string]authorization
+}
+
+type authorization struct {
+	challenge string
+	nonce     string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &mockProvider{key: key, codes: map[string]authorization{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                           p.URL,
+			"authorization_endpoint":           p.URL + "/authorize",
+			"token_endpoint":                   p.URL + "/token",
+			"jwks_uri":                         p.URL + "/jwks",
+			"code_challenge_methods_supported": []string{"S256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", p.handleToken)
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// authorize does what the provider does when the user logs in at the URL of
+// Begin, it returns the code and state the user is redirected back with
+func (p *mockProvider) authorize(t *testing.T, authURL string) (string, string) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != testClientID || query.Get("redirect_uri") != testRedirectURL {
+		t.Fatalf("unexpected authorization request %s", authURL)
+	}
+	code := oidc.NewCodeVerifier()
+	p.mu.Lock()
+	p.codes[code] = authorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	p.mu.Unlock()
+	return code, query.Get("state")
+}
+
+func (p *mockProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	if id, secret, ok := r.BasicAuth(); !ok || id != testClientID || secret != testClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	auth, ok := p.codes[r.Form.Get("code")]
+	delete(p.codes, r.Form.Get("code"))
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   p.URL,
+		"aud":   testClientID,
+		"sub":   "subject-1",
+		"nonce": auth.nonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+	}
+	for name, value := range p.claims {
+		claims[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "key-1"
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"access_token": "access", "token_type": "Bearer", "id_token": idToken})
+}
+
+func (p *mockProvider) setClaims(claims jwt.MapClaims) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.claims = claims
+}
+
+type oidcTest struct {
+	provider     *mockProvider
+	store        *memory.Store
+	service      *OIDCService
+	tokenService *TokenService
+}
+
+func newOIDCTest(t *testing.T, autoProvision bool) *oidcTest {
+	provider := newMockProvider(t)
+	store := memory.New()
+	tokenService := NewTokenService(&config.JWTConfig{
+		AccessSecret:    "access",
+		RefreshSecret:   "refresh",
+		AccessExpiry:    time.Minute,
+		RefreshExpiry:   time.Hour,
+		OIDCStateExpiry: time.Minute,
+	}, store, store)
+	cfg := &config.OIDCConfig{
+		IssuerURL:     provider.URL,
+		ClientID:      testClientID,
+		ClientSecret:  testClientSecret,
+		RedirectURL:   testRedirectURL,
+		Scopes:        []string{"openid", "email"},
+		AutoProvision: autoProvision,
+	}
+	client := oidc.NewProvider(cfg.IssuerURL, cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.Scopes)
+	service := NewOIDCService(cfg, client, store, store, store, store, tokenService)
+	return &oidcTest{provider: provider, store: store, service: service, tokenService: tokenService}
+}
+
+// login runs a whole login, the provider vouches for the user with claims
+func (o *oidcTest) login(t *testing.T, claims jwt.MapClaims) (*models.Auth, error) {
+	o.provider.setClaims(claims)
+	authURL, stateToken, err := o.service.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := o.provider.authorize(t, authURL)
+	return o.service.Complete(context.Background(), stateToken, state, code)
+}
+
+func verifiedEmail(email string) jwt.MapClaims {
+	return jwt.MapClaims{"email": email, "email_verified": true}
+}
+
+func TestOIDCProvisionsUser(t *testing.T) {
+	o := newOIDCTest(t, true)
+	user, err := o.login(t, jwt.MapClaims{"email": "ada@example.com", "email_verified": true, "preferred_username": "ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "ada" || user.Email != "ada@example.com" || !user.EmailVerified || user.Password != "" {
+		t.Fatalf("unexpected provisioned user %+v", user)
+	}
+	// the next login finds the same user by the subject, even with another email
+	again, err := o.login(t, verifiedEmail("ada@elsewhere.example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != user.ID {
+		t.Fatalf("second login got user %s, want %s", again.ID.Hex(), user.ID.Hex())
+	}
+}
+
+func TestOIDCProvisioningPicksFreeUsername(t *testing.T) {
+	o := newOIDCTest(t, true)
+	if _, err := RegisterUser(o.store, "ada", "other@example.com", "password"); err != nil {
+		t.Fatal(err)
+	}
+	user, err := o.login(t, jwt.MapClaims{"email": "ada@example.com", "email_verified": true, "preferred_username": "ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username == "ada" {
+		t.Fatal("provisioned user got a taken username")
+	}
+}
+
+func TestOIDCWithoutProvisioning(t *testing.T) {
+	o := newOIDCTest(t, false)
+	if _, err := o.login(t, verifiedEmail("ada@example.com")); err != ErrOIDCNoAccount {
+		t.Fatalf("got %v, want ErrOIDCNoAccount", err)
+	}
+}
+
+func TestOIDCLinksVerifiedAccount(t *testing.T) {
+	o := newOIDCTest(t, true)
+	ctx := context.Background()
+	existing, err := RegisterUser(o.store, "ada", "ada@example.com", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.store.MarkEmailVerified(ctx, existing.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.tokenService.GenerateTokens(ctx, existing, nil, "test", "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	user, err := o.login(t, verifiedEmail("ada@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != existing.ID {
+		t.Fatalf("logged in as %s, want the existing user %s", user.ID.Hex(), existing.ID.Hex())
+	}
+	// a verified account keeps its password and sessions
+	stored, _ := o.store.GetUserByID(ctx, existing.ID)
+	if utils.CheckPassword("password", stored.Password) != nil {
+		t.Fatal("linking removed the password of a verified account")
+	}
+	if sessions, _ := o.store.ListActiveSessions(ctx, existing.ID); len(sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(sessions))
+	}
+	identity, err := o.store.GetIdentity(ctx, o.provider.URL, "subject-1")
+	if err != nil || identity.UserID != existing.ID {
+		t.Fatalf("identity not linked: %+v %v", identity, err)
+	}
+}
+
+func TestOIDCReclaimsUnverifiedAccount(t *testing.T) {
+	o := newOIDCTest(t, true)
+	ctx := context.Background()
+	// someone registered the email before its owner logs in through the provider
+	squatter, err := RegisterUser(o.store, "squatter", "ada@example.com", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.tokenService.GenerateTokens(ctx, squatter, nil, "test", "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	claims := &models.AccessTokenClaims{UserID: squatter.ID, Scopes: models.DefaultScopes}
+	if _, _, err := NewAPIKeyService(o.store, o.store).Create(ctx, claims, "key", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.store.SaveMFA(ctx, &models.MFA{UserID: squatter.ID, Secret: "SECRET", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := o.login(t, verifiedEmail("ada@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != squatter.ID || !user.EmailVerified {
+		t.Fatalf("unexpected user %+v", user)
+	}
+	if _, err := LoginUser(o.store, "ada@example.com", "password"); err == nil {
+		t.Fatal("the password of the unverified account still works")
+	}
+	if sessions, _ := o.store.ListActiveSessions(ctx, squatter.ID); len(sessions) != 0 {
+		t.Fatalf("got %d sessions, want none", len(sessions))
+	}
+	if keys, _ := o.store.ListAPIKeys(ctx, squatter.ID); len(keys) != 0 {
+		t.Fatalf("got %d API keys, want none", len(keys))
+	}
+	if _, err := o.store.GetMFA(ctx, squatter.ID); err != storage.ErrNotFound {
+		t.Fatalf("second factor kept: %v", err)
+	}
+}
+
+func TestOIDCRejectsUnverifiedEmail(t *testing.T) {
+	o := newOIDCTest(t, true)
+	if _, err := RegisterUser(o.store, "ada", "ada@example.com", "password"); err != nil {
+		t.Fatal(err)
+	}
+	for _, claims := range []jwt.MapClaims{
+		{"email": "ada@example.com", "email_verified": false},
+		{"email": "ada@example.com"},
+		{"email_verified": true},
+	} {
+		if _, err := o.login(t, claims); err != ErrOIDCEmailNotVerified {
+			t.Errorf("claims %v: got %v, want ErrOIDCEmailNotVerified", claims, err)
+		}
+	}
+}
+
+func TestOIDCRejectsStateMismatch(t *testing.T) {
+	o := newOIDCTest(t, true)
+	ctx := context.Background()
+	authURL, stateToken, err := o.service.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := o.provider.authorize(t, authURL)
+	if _, err := o.service.Complete(ctx, stateToken, "other-state", code); err != ErrInvalidOIDCState {
+		t.Fatalf("got %v, want ErrInvalidOIDCState", err)
+	}
+	// the state token of another login does not fit either
+	_, otherToken, err := o.service.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, state := o.provider.authorize(t, authURL)
+	if _, err := o.service.Complete(ctx, otherToken, state, code); err != ErrInvalidOIDCState {
+		t.Fatalf("got %v, want ErrInvalidOIDCState", err)
+	}
+	if _, err := o.service.Complete(ctx, "not a token", state, code); err != ErrInvalidOIDCState {
+		t.Fatalf("got %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestOIDCRejectsPKCEVerifierMismatch(t *testing.T) {
+	o := newOIDCTest(t, true)
+	ctx := context.Background()
+	o.provider.setClaims(verifiedEmail("ada@example.com"))
+	authURL, _, err := o.service.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := o.provider.authorize(t, authURL)
+	// a state token with the right state but another verifier, the provider
+	// refuses the code since it was asked for with another challenge
+	if _, err := o.service.Complete(ctx, mustStateToken(t, o, state), state, code); err != ErrOIDCLoginFailed {
+		t.Fatalf("got %v, want ErrOIDCLoginFailed", err)
+	}
+}
+
+// mustStateToken returns a state token for state with a fresh nonce and verifier
+func mustStateToken(t *testing.T, o *oidcTest, state string) string {
+	token, err := o.tokenService.GenerateOIDCState(state, oidc.NewCodeVerifier(), oidc.NewCodeVerifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestOIDCRejectsInvalidIDTokens(t *testing.T) {
+	o := newOIDCTest(t, true)
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"wrong nonce", jwt.MapClaims{"nonce": "other"}},
+		{"wrong audience", jwt.MapClaims{"aud": "other-client"}},
+		{"several audiences without azp", jwt.MapClaims{"aud": []string{testClientID, "other-client"}}},
+		{"azp of another client", jwt.MapClaims{"aud": []string{testClientID, "other-client"}, "azp": "other-client"}},
+		{"wrong issuer", jwt.MapClaims{"iss": "https://issuer.example"}},
+		{"expired", jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}},
+		{"no subject", jwt.MapClaims{"sub": ""}},
+	}
+	for _, tt := range tests {
+		claims := verifiedEmail("ada@example.com")
+		for name, value := range tt.claims {
+			claims[name] = value
+		}
+		if _, err := o.login(t, claims); err != ErrOIDCLoginFailed {
+			t.Errorf("%s: got %v, want ErrOIDCLoginFailed", tt.name, err)
+		}
+	}
+	// a token for several clients is fine when it names this one
+	claims := verifiedEmail("ada@example.com")
+	claims["aud"] = []string{testClientID, "other-client"}
+	claims["azp"] = testClientID
+	if _, err := o.login(t, claims); err != nil {
+		t.Fatalf("azp of this client: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/config"
+	"github.com/Aym-Aymen777/RSS-Aggregator/mailer"
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/utils"
+)
+
+// ErrInvalidResetToken is returned for reset tokens that are unknown, used or expired
+var ErrInvalidResetToken = errors.New("invalid password reset token")
+
+// PasswordResetService mails single use reset tokens and resets the password
+// of the users who present them
+type PasswordResetService struct {
+	config       *config.PasswordResetConfig
+	users        storage.UserStore
+	resets       storage.PasswordResetStore
+	mailer       mailer.Mailer
+	tokenService *TokenService
+}
+
+func NewPasswordResetService(config *config.PasswordResetConfig, users storage.UserStore, resets storage.PasswordResetStore, mailer mailer.Mailer, tokenService *TokenService) *PasswordResetService {
+	return &PasswordResetService{config: config, users: users, resets: resets, mailer: mailer, tokenService: tokenService}
+}
+
+// RequestReset mails a reset link to the user with the email. An unknown
+// email is not an error, callers must not tell who is registered. The link is
+// created and mailed in the background, so the response takes as long for a
+// registered email as for an unknown one.
+func (s *PasswordResetService) RequestReset(ctx context.Context, email string) error {
+	user, err := s.users.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			log.Printf("Password reset requested for unknown email %s", email)
+			return nil
+		}
+		return err
+	}
+	inBackground("sending password reset", func(ctx context.Context) error {
+		return s.sendReset(ctx, user)
+	})
+	return nil
+}
+
+func (s *PasswordResetService) sendReset(ctx context.Context, user *models.Auth) error {
+	token := newSecretToken()
+	reset := &models.PasswordReset{
+		ID:        hashSecretToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.config.Expiry),
+		CreatedAt: time.Now(),
+	}
+	if err := s.resets.CreatePasswordReset(ctx, reset); err != nil {
+		return err
+	}
+	link := s.config.URL + "?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen the link below to choose a new password, it expires in %s:\n\n%s\n\n"+
+			"If you did not ask for a password reset you can ignore this email.\n", user.Username, s.config.Expiry, link),
+	})
+}
+
+// ResetPassword sets the password of the user the token was mailed to and
+// ends all of their sessions
+func (s *PasswordResetService) ResetPassword(ctx context.Context, token, password string) error {
+	reset, err := s.resets.ConsumePasswordReset(ctx, hashSecretToken(token))
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdatePassword(ctx, reset.UserID, hashedPassword); err != nil {
+		return err
+	}
+	return s.tokenService.RevokeUserSessions(ctx, reset.UserID)
+}
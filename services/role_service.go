@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+)
+
+// ErrInvalidRole is returned for roles that do not exist
+var ErrInvalidRole = errors.New("invalid role")
+
+// BootstrapAdmin gives the admin role to the registered user with the email,
+// it lets the first admin in before anyone can grant roles
+func BootstrapAdmin(ctx context.Context, users storage.UserStore, email string) error {
+	user, err := users.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			log.Printf("⚠️  Bootstrap admin %s is not registered yet, register and restart to promote it", email)
+			return nil
+		}
+		return err
+	}
+	roles := user.UserRoles()
+	if slices.Contains(roles, models.RoleAdmin) {
+		return nil
+	}
+	if err := users.SetUserRoles(ctx, user.ID, append(slices.Clone(roles), models.RoleAdmin)); err != nil {
+		return err
+	}
+	log.Printf("👑 Promoted %s to admin", email)
+	return nil
+}
+
+// SetUserRoles replaces the roles of the user with the email, every user keeps
+// the user role
+func SetUserRoles(ctx context.Context, users storage.UserStore, email string, roles []string) (*models.Auth, error) {
+	user, err := users.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	updated := []string{models.RoleUser}
+	for _, role := range roles {
+		if !models.ValidRole(role) {
+			return nil, ErrInvalidRole
+		}
+		if !slices.Contains(updated, role) {
+			updated = append(updated, role)
+		}
+	}
+	if err := users.SetUserRoles(ctx, user.ID, updated); err != nil {
+		return nil, err
+	}
+	user.Roles = updated
+	return user, nil
+}
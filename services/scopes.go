@@ -0,0 +1,43 @@
+package services
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrInvalidScope is returned for scopes that do not exist or can not be granted
+var ErrInvalidScope = errors.New("invalid scope")
+
+// grantScopes returns the requested scopes when they are all allowed, no
+// requested scope means all the allowed ones
+func grantScopes(allowed, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return slices.Clone(allowed), nil
+	}
+	granted := []string{}
+	for _, scope := range requested {
+		if !slices.Contains(allowed, scope) {
+			return nil, ErrInvalidScope
+		}
+		if !slices.Contains(granted, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	return granted, nil
+}
+
+// limitScopes returns the granted scopes that are still allowed, like after
+// the user lost a role. Credentials granted before scopes existed have none
+// and get all the allowed ones.
+func limitScopes(granted, allowed []string) []string {
+	if len(granted) == 0 {
+		return slices.Clone(allowed)
+	}
+	limited := []string{}
+	for _, scope := range granted {
+		if slices.Contains(allowed, scope) {
+			limited = append(limited, scope)
+		}
+	}
+	return limited
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// newSecretToken returns a random token to send in an email link
+func newSecretToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// hashSecretToken returns the hash a token or a key handed out to a user is
+// stored under, a leaked database does not give away usable secrets
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,29 +1,56 @@
 package services
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
 	"github.com/Aym-Aymen777/RSS-Aggregator/config"
 	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
 	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+var (
+	// ErrInvalidRefreshToken is returned for refresh tokens that are unknown or revoked
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when a refresh token that was already
+	// rotated is presented again. It may have been stolen, so its whole family
+	// is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrSessionRevoked is returned for access tokens of a session that was
+	// revoked or has expired
+	ErrSessionRevoked = errors.New("session revoked")
+)
+
+// sessionTouchInterval limits how often requests with access tokens update
+// the last use of their session
+const sessionTouchInterval = time.Minute
+
 type TokenService struct {
-	config *config.JWTConfig
+	config   *config.JWTConfig
+	tokens   storage.TokenStore
+	sessions storage.SessionStore
 }
 
-func NewTokenService(config *config.JWTConfig) *TokenService {
-	return &TokenService{config: config}
+func NewTokenService(config *config.JWTConfig, tokens storage.TokenStore, sessions storage.SessionStore) *TokenService {
+	return &TokenService{config: config, tokens: tokens, sessions: sessions}
 }
 
 // I generate access token
-func (s *TokenService) GenerateAccessToken(userID bson.ObjectID, email string) (string, error) {
-	// Create claims with user ID and email
+func (s *TokenService) GenerateAccessToken(user *models.Auth, sessionID bson.ObjectID, scopes []string) (string, error) {
+	// Create claims with user ID, email, roles, scopes and session
 	claims := models.AccessTokenClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:    user.ID,
+		Email:     user.Email,
+		SessionID: sessionID,
+		Roles:     user.UserRoles(),
+		Scopes:    scopes,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.AccessExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -42,12 +69,22 @@ func (s *TokenService) GenerateAccessToken(userID bson.ObjectID, email string) (
 	return tokenString, nil
 }
 
-// II gnerate refresh token
-func (s *TokenService) GenerateRefreshToken(userID bson.ObjectID) (string, error) {
+// II gnerate refresh token with a new jti in the family, and its record to store
+func (s *TokenService) GenerateRefreshToken(userID bson.ObjectID, email, familyID string) (string, *models.RefreshToken, error) {
+	record := &models.RefreshToken{
+		ID:        newTokenID(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(s.config.RefreshExpiry),
+		CreatedAt: time.Now(),
+	}
 	claims := models.RefreshTokenClaims{
-		UserID: userID,
+		UserID:   userID,
+		Email:    email,
+		FamilyID: familyID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.RefreshExpiry)),
+			ID:        record.ID,
+			ExpiresAt: jwt.NewNumericDate(record.ExpiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
@@ -55,27 +92,194 @@ func (s *TokenService) GenerateRefreshToken(userID bson.ObjectID) (string, error
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(s.config.RefreshSecret))
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
-	return tokenString, nil
+	return tokenString, record, nil
 }
 
-// III generate both tokens and return them as a struct
-func (s *TokenService) GenerateTokens(userID bson.ObjectID, email string) (*models.TokenResponse, error) {
-	accessToken, err := s.GenerateAccessToken(userID, email)
+// III generate both tokens of a new login, the login opens a session from the
+// client and the refresh token starts the family of the session. The session
+// gets the requested scopes, or all the scopes the roles of the user allow.
+func (s *TokenService) GenerateTokens(ctx context.Context, user *models.Auth, scopes []string, userAgent, ip string) (*models.TokenResponse, error) {
+	scopes, err := grantScopes(models.ScopesForRoles(user.UserRoles()), scopes)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &models.Session{
+		UserID:     user.ID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		Scopes:     scopes,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.config.RefreshExpiry),
+	}
+	if err := s.sessions.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+	accessToken, err := s.GenerateAccessToken(user, session.ID, scopes)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, record, err := s.GenerateRefreshToken(user.ID, user.Email, session.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.SaveRefreshToken(ctx, record); err != nil {
+		return nil, err
+	}
+	return &models.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// RotateTokens exchanges a validated refresh token for a new pair with the
+// current email and roles of the user and the scopes of the session, the
+// presented token is revoked and can not be used again
+func (s *TokenService) RotateTokens(ctx context.Context, claims *models.RefreshTokenClaims, user *models.Auth) (*models.TokenResponse, error) {
+	record, err := s.tokens.GetRefreshToken(ctx, claims.ID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if record.UserID != claims.UserID || record.UserID != user.ID {
+		return nil, ErrInvalidRefreshToken
+	}
+	sessionID, err := bson.ObjectIDFromHex(record.FamilyID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if record.RevokedAt != nil {
+		if record.ReplacedBy != "" {
+			if err := s.revokeSession(ctx, sessionID); err != nil {
+				return nil, err
+			}
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	session, err := s.sessions.GetSession(ctx, sessionID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	scopes := limitScopes(session.Scopes, models.ScopesForRoles(user.UserRoles()))
+	accessToken, err := s.GenerateAccessToken(user, sessionID, scopes)
 	if err != nil {
 		return nil, err
 	}
-	refreshToken, err := s.GenerateRefreshToken(userID)
+	refreshToken, next, err := s.GenerateRefreshToken(user.ID, user.Email, record.FamilyID)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.tokens.RotateRefreshToken(ctx, record.ID, next); err != nil {
+		// another request rotated the token in the meantime
+		if err == storage.ErrNotFound {
+			if err := s.revokeSession(ctx, sessionID); err != nil {
+				return nil, err
+			}
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, err
+	}
+	if err := s.sessions.TouchSession(ctx, sessionID, next.CreatedAt, next.ExpiresAt); err != nil {
+		return nil, err
+	}
 	return &models.TokenResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}, nil
 }
 
+// ListSessions returns the active sessions of the user, the most recently used first
+func (s *TokenService) ListSessions(ctx context.Context, userID bson.ObjectID) ([]models.Session, error) {
+	return s.sessions.ListActiveSessions(ctx, userID)
+}
+
+// RevokeSession ends a session of the user: its refresh tokens are revoked
+// and its access tokens are rejected from now on. It returns
+// storage.ErrNotFound when the user has no such active session.
+func (s *TokenService) RevokeSession(ctx context.Context, userID, sessionID bson.ObjectID) error {
+	session, err := s.sessions.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID || !session.Active(time.Now()) {
+		return storage.ErrNotFound
+	}
+	return s.revokeSession(ctx, sessionID)
+}
+
+// RevokeOtherSessions ends every session of the user except the current one
+// and returns how many were ended
+func (s *TokenService) RevokeOtherSessions(ctx context.Context, userID, currentID bson.ObjectID) (int, error) {
+	sessions, err := s.sessions.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == currentID {
+			continue
+		}
+		if err := s.revokeSession(ctx, session.ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// RevokeUserSessions ends every session of the user, with their refresh tokens
+func (s *TokenService) RevokeUserSessions(ctx context.Context, userID bson.ObjectID) error {
+	if err := s.sessions.RevokeUserSessions(ctx, userID); err != nil {
+		return err
+	}
+	return s.tokens.RevokeUserRefreshTokens(ctx, userID)
+}
+
+// ValidateSession checks that the session of validated access token claims is
+// still active, and records the use of the session
+func (s *TokenService) ValidateSession(ctx context.Context, claims *models.AccessTokenClaims) error {
+	session, err := s.sessions.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+	now := time.Now()
+	if session.UserID != claims.UserID || !session.Active(now) {
+		return ErrSessionRevoked
+	}
+	if now.Sub(session.LastUsedAt) > sessionTouchInterval {
+		return s.sessions.TouchSession(ctx, session.ID, now, time.Time{})
+	}
+	return nil
+}
+
+// revokeSession revokes the session and its refresh token family, a session
+// that is already revoked is not an error
+func (s *TokenService) revokeSession(ctx context.Context, sessionID bson.ObjectID) error {
+	if err := s.sessions.RevokeSession(ctx, sessionID); err != nil && err != storage.ErrNotFound {
+		return err
+	}
+	return s.tokens.RevokeRefreshTokenFamily(ctx, sessionID.Hex())
+}
+
+// newTokenID returns a random id for the jti of refresh tokens
+func newTokenID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 // IV validate access token
 func (s *TokenService) ValidateAccessToken(tokenString string) (*models.AccessTokenClaims, error) {
 	// parse the token
@@ -99,6 +303,89 @@ func (s *TokenService) ValidateAccessToken(tokenString string) (*models.AccessTo
 	return claims, nil
 }
 
+// GenerateMFAChallenge returns the token a password login answers with when
+// the user has a second factor, it is exchanged for the tokens with a code
+func (s *TokenService) GenerateMFAChallenge(userID bson.ObjectID, email string, scopes []string) (string, error) {
+	claims := models.MFAChallengeClaims{
+		UserID: userID,
+		Email:  email,
+		Scopes: scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.MFAExpiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.derivedKey("mfa-challenge"))
+}
+
+func (s *TokenService) ValidateMFAChallenge(tokenString string) (*models.MFAChallengeClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &models.MFAChallengeClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return s.derivedKey("mfa-challenge"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*models.MFAChallengeClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid mfa challenge")
+	}
+	return claims, nil
+}
+
+// GenerateOIDCState returns the token that keeps the state, nonce and PKCE
+// verifier of an OpenID Connect login in the browser until the provider
+// redirects back to the callback
+func (s *TokenService) GenerateOIDCState(state, nonce, codeVerifier string) (string, error) {
+	claims := models.OIDCStateClaims{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.OIDCStateExpiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.derivedKey("oidc-state"))
+}
+
+// OIDCStateExpiry is the lifetime of the tokens of GenerateOIDCState
+func (s *TokenService) OIDCStateExpiry() time.Duration {
+	return s.config.OIDCStateExpiry
+}
+
+func (s *TokenService) ValidateOIDCState(tokenString string) (*models.OIDCStateClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &models.OIDCStateClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return s.derivedKey("oidc-state"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*models.OIDCStateClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid oidc state")
+	}
+	return claims, nil
+}
+
+// derivedKey derives the key of MFA challenges and OIDC states from the
+// access token secret, so neither is ever accepted as an access token or as
+// the other
+func (s *TokenService) derivedKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(s.config.AccessSecret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
 // V validate refresh token
 func (s *TokenService) ValidateRefreshToken(tokenString string) (*models.RefreshTokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &models.RefreshTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
@@ -112,8 +399,9 @@ func (s *TokenService) ValidateRefreshToken(tokenString string) (*models.Refresh
 		return nil, err
 	}
 
+	// tokens without a jti were issued before refresh tokens were recorded
 	claims, ok := token.Claims.(*models.RefreshTokenClaims)
-	if !ok || !token.Valid {
+	if !ok || !token.Valid || claims.ID == "" {
 		return nil, errors.New("invalid refresh token")
 	}
 
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+// ItemKey identifies an item inside its feed: the guid when the feed gives one,
+// else a hash of the link and title
+func ItemKey(post models.Post) string {
+	if post.GUID != "" {
+		return "guid:" + post.GUID
+	}
+	return "hash:" + hashStrings(post.Link, post.Title)
+}
+
+// ContentHash changes when an item is edited
+func ContentHash(post models.Post) string {
+	enclosure := ""
+	if post.Enclosure != nil {
+		enclosure = post.Enclosure.URL
+	}
+	return hashStrings(post.Title, post.Description, post.Content, post.Link, post.Author,
+		strings.Join(post.Categories, ","), enclosure, post.PublishedAt.UTC().Format(time.RFC3339))
+}
+
+func hashStrings(values ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(values, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
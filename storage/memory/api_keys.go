@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.apiKeys {
+		if existing.KeyHash == key.KeyHash {
+			return storage.ErrDuplicate
+		}
+	}
+	key.ID = bson.NewObjectID()
+	saved := *key
+	saved.Scopes = slices.Clone(key.Scopes)
+	s.apiKeys[key.ID] = saved
+	return nil
+}
+
+func (s *Store) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, key := range s.apiKeys {
+		if key.KeyHash == keyHash {
+			key.Scopes = slices.Clone(key.Scopes)
+			return &key, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (s *Store) ListAPIKeys(ctx context.Context, userID bson.ObjectID) ([]models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var keys []models.APIKey
+	for _, key := range s.apiKeys {
+		if key.UserID == userID && key.RevokedAt == nil {
+			key.Scopes = slices.Clone(key.Scopes)
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+func (s *Store) TouchAPIKey(ctx context.Context, id bson.ObjectID, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.apiKeys[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	key.LastUsedAt = &usedAt
+	s.apiKeys[id] = key
+	return nil
+}
+
+func (s *Store) RevokeAPIKey(ctx context.Context, userID, id bson.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.apiKeys[id]
+	if !ok || key.UserID != userID || key.RevokedAt != nil {
+		return storage.ErrNotFound
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	s.apiKeys[id] = key
+	return nil
+}
+
+func (s *Store) RevokeUserAPIKeys(ctx context.Context, userID bson.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for id, key := range s.apiKeys {
+		if key.UserID == userID && key.RevokedAt == nil {
+			key.RevokedAt = &now
+			s.apiKeys[id] = key
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+)
+
+func (s *Store) CreateEmailVerification(ctx context.Context, verification *models.EmailVerification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.verifies[verification.ID]; ok {
+		return storage.ErrDuplicate
+	}
+	s.verifies[verification.ID] = *verification
+	return nil
+}
+
+func (s *Store) ConsumeEmailVerification(ctx context.Context, id string) (*models.EmailVerification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	verification, ok := s.verifies[id]
+	if !ok || verification.UsedAt != nil || !now.Before(verification.ExpiresAt) {
+		return nil, storage.ErrNotFound
+	}
+	for key, other := range s.verifies {
+		if other.UserID == verification.UserID && other.UsedAt == nil {
+			other.UsedAt = &now
+			s.verifies[key] = other
+		}
+	}
+	verification.UsedAt = &now
+	return &verification, nil
+}
@@ -0,0 +1,254 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) CreateFeed(ctx context.Context, feed *models.Feed) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	feed.ID = bson.NewObjectID()
+	s.feeds[feed.ID] = *feed
+	return nil
+}
+
+func (s *Store) GetFeed(ctx context.Context, id bson.ObjectID) (*models.Feed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	feed, ok := s.feeds[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &feed, nil
+}
+
+func (s *Store) GetFeedByURL(ctx context.Context, userID bson.ObjectID, url string) (*models.Feed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, feed := range s.feeds {
+		if feed.UserID == userID && feed.URL == url {
+			return &feed, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (s *Store) FindFeedByURL(ctx context.Context, url string) (*models.Feed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var found *models.Feed
+	for _, feed := range s.feeds {
+		if feed.URL == url && (found == nil || feed.CreatedAt.Before(found.CreatedAt)) {
+			found = &feed
+		}
+	}
+	if found == nil {
+		return nil, storage.ErrNotFound
+	}
+	return found, nil
+}
+
+func (s *Store) ListFeedsByUser(ctx context.Context, userID bson.ObjectID) ([]models.Feed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	feeds := []models.Feed{}
+	for _, feed := range s.feeds {
+		if feed.UserID == userID {
+			feeds = append(feeds, feed)
+		}
+	}
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].CreatedAt.Before(feeds[j].CreatedAt) })
+	return feeds, nil
+}
+
+func (s *Store) DeleteFeed(ctx context.Context, id bson.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.feeds[id]; !ok {
+		return storage.ErrNotFound
+	}
+	delete(s.feeds, id)
+	for followID, follow := range s.follows {
+		if follow.FeedID == id {
+			delete(s.follows, followID)
+		}
+	}
+	for postID, post := range s.posts {
+		if post.FeedID == id {
+			delete(s.posts, postID)
+		}
+	}
+	for key, state := range s.states {
+		if state.FeedID == id {
+			delete(s.states, key)
+		}
+	}
+	return nil
+}
+
+func (s *Store) NextFeedsToFetch(ctx context.Context, limit int) ([]models.Feed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	feeds := make([]models.Feed, 0, len(s.feeds))
+	for _, feed := range s.feeds {
+		feeds = append(feeds, feed)
+	}
+	sort.Slice(feeds, func(i, j int) bool {
+		a, b := feeds[i].LastFetchedAt, feeds[j].LastFetchedAt
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Before(*b)
+	})
+	if len(feeds) > limit {
+		feeds = feeds[:limit]
+	}
+	return feeds, nil
+}
+
+func (s *Store) MarkFeedFetched(ctx context.Context, id bson.ObjectID, status int, etag, lastModified string) error {
+	return s.updateFeed(id, func(feed *models.Feed) {
+		now := time.Now()
+		feed.LastFetchedAt = &now
+		feed.LastStatus = status
+		feed.ETag = etag
+		feed.LastModified = lastModified
+		feed.ErrorCount = 0
+		feed.UpdatedAt = now
+	})
+}
+
+func (s *Store) MarkFeedFailed(ctx context.Context, id bson.ObjectID, status int) error {
+	return s.updateFeed(id, func(feed *models.Feed) {
+		now := time.Now()
+		feed.LastFetchedAt = &now
+		feed.LastStatus = status
+		feed.ErrorCount++
+		feed.UpdatedAt = now
+	})
+}
+
+func (s *Store) FillFeedInfo(ctx context.Context, id bson.ObjectID, title, siteLink string) error {
+	return s.updateFeed(id, func(feed *models.Feed) {
+		if feed.Title == "" {
+			feed.Title = title
+		}
+		if feed.SiteLink == "" {
+			feed.SiteLink = siteLink
+		}
+	})
+}
+
+func (s *Store) updateFeed(id bson.ObjectID, update func(*models.Feed)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	feed, ok := s.feeds[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	update(&feed)
+	s.feeds[id] = feed
+	return nil
+}
+
+func (s *Store) FollowFeed(ctx context.Context, userID, feedID bson.ObjectID) (*models.FeedFollow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, follow := range s.follows {
+		if follow.UserID == userID && follow.FeedID == feedID {
+			return &follow, nil
+		}
+	}
+	follow := models.FeedFollow{
+		ID:        bson.NewObjectID(),
+		UserID:    userID,
+		FeedID:    feedID,
+		CreatedAt: time.Now(),
+	}
+	s.follows[follow.ID] = follow
+	return &follow, nil
+}
+
+func (s *Store) ListFollows(ctx context.Context, userID bson.ObjectID) ([]models.FeedFollow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.userFollows(userID), nil
+}
+
+func (s *Store) userFollows(userID bson.ObjectID) []models.FeedFollow {
+	follows := []models.FeedFollow{}
+	for _, follow := range s.follows {
+		if follow.UserID == userID {
+			follows = append(follows, follow)
+		}
+	}
+	sort.Slice(follows, func(i, j int) bool { return follows[i].CreatedAt.Before(follows[j].CreatedAt) })
+	return follows
+}
+
+func (s *Store) DeleteFollow(ctx context.Context, userID, followID bson.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	follow, ok := s.follows[followID]
+	if !ok || follow.UserID != userID {
+		return storage.ErrNotFound
+	}
+	delete(s.follows, followID)
+	return nil
+}
+
+func (s *Store) IsFollowing(ctx context.Context, userID, feedID bson.ObjectID) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, follow := range s.follows {
+		if follow.UserID == userID && follow.FeedID == feedID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Store) HasOtherFollowers(ctx context.Context, feedID, userID bson.ObjectID) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, follow := range s.follows {
+		if follow.FeedID == feedID && follow.UserID != userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Store) FollowedFeedIDs(ctx context.Context, userID bson.ObjectID) ([]bson.ObjectID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.followedFeedIDs(userID), nil
+}
+
+func (s *Store) ListFollowedFeeds(ctx context.Context, userID bson.ObjectID) ([]models.Feed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	feeds := []models.Feed{}
+	for _, id := range s.followedFeedIDs(userID) {
+		if feed, ok := s.feeds[id]; ok {
+			feeds = append(feeds, feed)
+		}
+	}
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].CreatedAt.Before(feeds[j].CreatedAt) })
+	return feeds, nil
+}
+
+func (s *Store) followedFeedIDs(userID bson.ObjectID) []bson.ObjectID {
+	follows := s.userFollows(userID)
+	ids := make([]bson.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		ids = append(ids, follow.FeedID)
+	}
+	return ids
+}
@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) CreateIdentity(ctx context.Context, identity *models.Identity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.idents {
+		if existing.Issuer == identity.Issuer && existing.Subject == identity.Subject {
+			return storage.ErrDuplicate
+		}
+	}
+	identity.ID = bson.NewObjectID()
+	s.idents[identity.ID] = *identity
+	return nil
+}
+
+func (s *Store) GetIdentity(ctx context.Context, issuer, subject string) (*models.Identity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, identity := range s.idents {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
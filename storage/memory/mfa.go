@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) GetMFA(ctx context.Context, userID bson.ObjectID) (*models.MFA, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	mfa, ok := s.mfa[userID]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	mfa.RecoveryCodes = slices.Clone(mfa.RecoveryCodes)
+	return &mfa, nil
+}
+
+func (s *Store) SaveMFA(ctx context.Context, mfa *models.MFA) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *mfa
+	saved.RecoveryCodes = slices.Clone(mfa.RecoveryCodes)
+	s.mfa[mfa.UserID] = saved
+	return nil
+}
+
+func (s *Store) DeleteMFA(ctx context.Context, userID bson.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.mfa[userID]; !ok {
+		return storage.ErrNotFound
+	}
+	delete(s.mfa, userID)
+	return nil
+}
+
+func (s *Store) UseTOTPStep(ctx context.Context, userID bson.ObjectID, step int64) error {
+	return s.updateMFA(userID, func(mfa *models.MFA) bool {
+		if mfa.LastStep >= step {
+			return false
+		}
+		mfa.LastStep = step
+		mfa.FailedAttempts = 0
+		mfa.LastFailureAt = nil
+		return true
+	})
+}
+
+func (s *Store) UseRecoveryCode(ctx context.Context, userID bson.ObjectID, codeHash string) error {
+	return s.updateMFA(userID, func(mfa *models.MFA) bool {
+		i := slices.Index(mfa.RecoveryCodes, codeHash)
+		if i < 0 {
+			return false
+		}
+		mfa.RecoveryCodes = slices.Delete(slices.Clone(mfa.RecoveryCodes), i, i+1)
+		mfa.FailedAttempts = 0
+		mfa.LastFailureAt = nil
+		return true
+	})
+}
+
+func (s *Store) RecordMFAFailure(ctx context.Context, userID bson.ObjectID) error {
+	return s.updateMFA(userID, func(mfa *models.MFA) bool {
+		now := time.Now()
+		mfa.FailedAttempts++
+		mfa.LastFailureAt = &now
+		return true
+	})
+}
+
+// updateMFA applies update to the second factor of the user and saves it when
+// update returns true
+func (s *Store) updateMFA(userID bson.ObjectID, update func(*models.MFA) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mfa, ok := s.mfa[userID]
+	if !ok || !update(&mfa) {
+		return storage.ErrNotFound
+	}
+	s.mfa[userID] = mfa
+	return nil
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+)
+
+func (s *Store) CreatePasswordReset(ctx context.Context, reset *models.PasswordReset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.resets[reset.ID]; ok {
+		return storage.ErrDuplicate
+	}
+	s.resets[reset.ID] = *reset
+	return nil
+}
+
+func (s *Store) ConsumePasswordReset(ctx context.Context, id string) (*models.PasswordReset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	reset, ok := s.resets[id]
+	if !ok || reset.UsedAt != nil || !now.Before(reset.ExpiresAt) {
+		return nil, storage.ErrNotFound
+	}
+	for key, other := range s.resets {
+		if other.UserID == reset.UserID && other.UsedAt == nil {
+			other.UsedAt = &now
+			s.resets[key] = other
+		}
+	}
+	reset.UsedAt = &now
+	return &reset, nil
+}
@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) CreatePost(ctx context.Context, post *models.Post) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post.ID = bson.NewObjectID()
+	s.posts[post.ID] = *post
+	return nil
+}
+
+func (s *Store) GetPost(ctx context.Context, id bson.ObjectID) (*models.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	post, ok := s.posts[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &post, nil
+}
+
+func (s *Store) GetVisiblePost(ctx context.Context, userID, id bson.ObjectID) (*models.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	post, ok := s.posts[id]
+	if !ok || !s.isVisible(post, userID, s.followedFeedIDs(userID)) {
+		return nil, storage.ErrNotFound
+	}
+	return &post, nil
+}
+
+func (s *Store) UpdatePost(ctx context.Context, id bson.ObjectID, update storage.PostUpdate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post, ok := s.posts[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	if update.Title != nil {
+		post.Title = *update.Title
+	}
+	if update.Description != nil {
+		post.Description = *update.Description
+	}
+	if update.Link != nil {
+		post.Link = *update.Link
+	}
+	post.UpdatedAt = time.Now()
+	s.posts[id] = post
+	return nil
+}
+
+func (s *Store) DeletePost(ctx context.Context, id bson.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.posts[id]; !ok {
+		return storage.ErrNotFound
+	}
+	delete(s.posts, id)
+	for key := range s.states {
+		if key.postID == id {
+			delete(s.states, key)
+		}
+	}
+	return nil
+}
+
+func (s *Store) ListPosts(ctx context.Context, userID bson.ObjectID, query storage.PostListQuery) ([]models.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	feedIDs := s.followedFeedIDs(userID)
+	posts := []models.Post{}
+	for _, post := range s.posts {
+		if !s.isVisible(post, userID, feedIDs) || !matchesFilter(post, query.PostFilter) {
+			continue
+		}
+		state := s.states[stateKey{userID, post.ID}]
+		if (query.UnreadOnly && state.Read) || (query.StarredOnly && !state.Starred) {
+			continue
+		}
+		if query.After != nil {
+			cmp := comparePosts(post, query.After.PublishedAt, query.After.ID)
+			if (query.Ascending && cmp <= 0) || (!query.Ascending && cmp >= 0) {
+				continue
+			}
+		}
+		posts = append(posts, post)
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		cmp := comparePosts(posts[i], posts[j].PublishedAt, posts[j].ID)
+		if query.Ascending {
+			return cmp < 0
+		}
+		return cmp > 0
+	})
+	if len(posts) > query.Limit {
+		posts = posts[:query.Limit]
+	}
+	return posts, nil
+}
+
+func (s *Store) SearchPosts(ctx context.Context, userID bson.ObjectID, query storage.PostSearchQuery) ([]storage.ScoredPost, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	text := storage.ParseTextQuery(query.Text)
+	feedIDs := s.followedFeedIDs(userID)
+	results := []storage.ScoredPost{}
+	for _, post := range s.posts {
+		if !s.isVisible(post, userID, feedIDs) || !matchesFilter(post, query.PostFilter) {
+			continue
+		}
+		if score := text.Score(post); score > 0 {
+			results = append(results, storage.ScoredPost{Post: post, Score: score})
+		}
+	}
+	return storage.SortScoredPosts(results, query.Offset, query.Limit), nil
+}
+
+func (s *Store) GetVisiblePosts(ctx context.Context, userID bson.ObjectID, ids []bson.ObjectID) ([]models.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	feedIDs := s.followedFeedIDs(userID)
+	posts := []models.Post{}
+	for _, id := range ids {
+		if post, ok := s.posts[id]; ok && s.isVisible(post, userID, feedIDs) {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (s *Store) UpsertFeedPost(ctx context.Context, post models.Post) (inserted, updated bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post.ItemKey = storage.ItemKey(post)
+	post.ContentHash = storage.ContentHash(post)
+	now := time.Now()
+	for id, existing := range s.posts {
+		if existing.FeedID != post.FeedID || existing.ItemKey != post.ItemKey {
+			continue
+		}
+		if existing.ContentHash == post.ContentHash {
+			return false, false, nil
+		}
+		post.ID = id
+		post.CreatedAt = existing.CreatedAt
+		// undated items keep the time they were first seen
+		if post.PublishedAt.IsZero() {
+			post.PublishedAt = existing.PublishedAt
+		}
+		post.UpdatedAt = now
+		s.posts[id] = post
+		return false, true, nil
+	}
+	post.ID = bson.NewObjectID()
+	post.CreatedAt = now
+	post.UpdatedAt = now
+	if post.PublishedAt.IsZero() {
+		post.PublishedAt = now
+	}
+	s.posts[post.ID] = post
+	return true, false, nil
+}
+
+func (s *Store) SetPostsRead(ctx context.Context, userID bson.ObjectID, posts []models.Post, read bool) error {
+	now := time.Now()
+	s.updateStates(userID, posts, func(state *models.PostState) {
+		state.Read = read
+		if read {
+			state.ReadAt = &now
+		}
+	})
+	return nil
+}
+
+func (s *Store) MarkFeedRead(ctx context.Context, userID, feedID bson.ObjectID, before *time.Time) (int64, error) {
+	s.mu.RLock()
+	posts := []models.Post{}
+	for _, post := range s.posts {
+		if post.FeedID == feedID && (before == nil || !post.PublishedAt.After(*before)) {
+			posts = append(posts, post)
+		}
+	}
+	s.mu.RUnlock()
+	return int64(len(posts)), s.SetPostsRead(ctx, userID, posts, true)
+}
+
+func (s *Store) SetPostStarred(ctx context.Context, userID bson.ObjectID, post models.Post, starred bool) error {
+	now := time.Now()
+	s.updateStates(userID, []models.Post{post}, func(state *models.PostState) {
+		state.Starred = starred
+		if starred {
+			state.StarredAt = &now
+		}
+	})
+	return nil
+}
+
+func (s *Store) updateStates(userID bson.ObjectID, posts []models.Post, update func(*models.PostState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, post := range posts {
+		key := stateKey{userID, post.ID}
+		state, ok := s.states[key]
+		if !ok {
+			state = models.PostState{ID: bson.NewObjectID(), UserID: userID, PostID: post.ID, FeedID: post.FeedID}
+		}
+		update(&state)
+		state.UpdatedAt = time.Now()
+		s.states[key] = state
+	}
+}
+
+func (s *Store) GetPostStates(ctx context.Context, userID bson.ObjectID, postIDs []bson.ObjectID) (map[bson.ObjectID]models.PostState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	states := make(map[bson.ObjectID]models.PostState, len(postIDs))
+	for _, id := range postIDs {
+		if state, ok := s.states[stateKey{userID, id}]; ok {
+			states[id] = state
+		}
+	}
+	return states, nil
+}
+
+func (s *Store) CountUnreadPosts(ctx context.Context, userID bson.ObjectID, feedIDs []bson.ObjectID) (map[bson.ObjectID]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	unread := make(map[bson.ObjectID]int64, len(feedIDs))
+	for _, feedID := range feedIDs {
+		unread[feedID] = 0
+	}
+	for _, post := range s.posts {
+		if _, ok := unread[post.FeedID]; ok && !s.states[stateKey{userID, post.ID}].Read {
+			unread[post.FeedID]++
+		}
+	}
+	return unread, nil
+}
+
+// isVisible reports if the post belongs to a followed feed or to the user
+func (s *Store) isVisible(post models.Post, userID bson.ObjectID, feedIDs []bson.ObjectID) bool {
+	return post.UserID == userID || (!post.FeedID.IsZero() && slices.Contains(feedIDs, post.FeedID))
+}
+
+func matchesFilter(post models.Post, f storage.PostFilter) bool {
+	switch {
+	case f.FeedID != nil && post.FeedID != *f.FeedID:
+		return false
+	case f.Category != "" && !slices.Contains(post.Categories, f.Category):
+		return false
+	case f.Author != "" && post.Author != f.Author:
+		return false
+	case f.Since != nil && post.PublishedAt.Before(*f.Since):
+		return false
+	case f.Until != nil && !post.PublishedAt.Before(*f.Until):
+		return false
+	}
+	return true
+}
+
+// comparePosts orders a post against a (published_at, id) position
+func comparePosts(post models.Post, publishedAt time.Time, id bson.ObjectID) int {
+	if c := post.PublishedAt.Compare(publishedAt); c != 0 {
+		return c
+	}
+	return bytes.Compare(post.ID[:], id[:])
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) CreateProfiles(ctx context.Context, profiles []models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range profiles {
+		profiles[i].ID = bson.NewObjectID()
+		s.profiles[profiles[i].ID] = profiles[i]
+	}
+	return nil
+}
+
+func (s *Store) FindProfilesByEmail(ctx context.Context, email string) ([]models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	profiles := []models.User{}
+	for _, profile := range s.profiles {
+		if profile.Email == email {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, nil
+}
+
+func (s *Store) UpdateProfile(ctx context.Context, id bson.ObjectID, update storage.ProfileUpdate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profile, ok := s.profiles[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	profile.Name = update.Name
+	profile.Age += update.AgeIncrement
+	profile.UpdatedAt = time.Now()
+	s.profiles[id] = profile
+	return nil
+}
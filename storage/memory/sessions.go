@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) CreateSession(ctx context.Context, session *models.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session.ID = bson.NewObjectID()
+	saved := *session
+	saved.Scopes = slices.Clone(session.Scopes)
+	s.sessions[session.ID] = saved
+	return nil
+}
+
+func (s *Store) GetSession(ctx context.Context, id bson.ObjectID) (*models.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &session, nil
+}
+
+func (s *Store) ListActiveSessions(ctx context.Context, userID bson.ObjectID) ([]models.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+	var sessions []models.Session
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.Active(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+func (s *Store) TouchSession(ctx context.Context, id bson.ObjectID, usedAt, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	session.LastUsedAt = usedAt
+	if !expiresAt.IsZero() {
+		session.ExpiresAt = expiresAt
+	}
+	s.sessions[id] = session
+	return nil
+}
+
+func (s *Store) RevokeSession(ctx context.Context, id bson.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return storage.ErrNotFound
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	s.sessions[id] = session
+	return nil
+}
+
+func (s *Store) RevokeUserSessions(ctx context.Context, userID bson.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for id, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			s.sessions[id] = session
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Package memory implements the storage interfaces in memory, it is meant for
+// tests and throwaway local runs: nothing survives a restart.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Store implements storage.Store. Records are stored by value, callers get copies of them.
+type Store struct {
+	mu       sync.RWMutex
+	users    map[bson.ObjectID]models.Auth
+	profiles map[bson.ObjectID]models.User
+	feeds    map[bson.ObjectID]models.Feed
+	follows  map[bson.ObjectID]models.FeedFollow
+	posts    map[bson.ObjectID]models.Post
+	states   map[stateKey]models.PostState
+	tokens   map[string]models.RefreshToken
+	sessions map[bson.ObjectID]models.Session
+	resets   map[string]models.PasswordReset
+	verifies map[string]models.EmailVerification
+	mfa      map[bson.ObjectID]models.MFA
+	apiKeys  map[bson.ObjectID]models.APIKey
+	idents   map[bson.ObjectID]models.Identity
+}
+
+type stateKey struct {
+	userID bson.ObjectID
+	postID bson.ObjectID
+}
+
+func New() *Store {
+	return &Store{
+		users:    map[bson.ObjectID]models.Auth{},
+		profiles: map[bson.ObjectID]models.User{},
+		feeds:    map[bson.ObjectID]models.Feed{},
+		follows:  map[bson.ObjectID]models.FeedFollow{},
+		posts:    map[bson.ObjectID]models.Post{},
+		states:   map[stateKey]models.PostState{},
+		tokens:   map[string]models.RefreshToken{},
+		sessions: map[bson.ObjectID]models.Session{},
+		resets:   map[string]models.PasswordReset{},
+		verifies: map[string]models.EmailVerification{},
+		mfa:      map[bson.ObjectID]models.MFA{},
+		apiKeys:  map[bson.ObjectID]models.APIKey{},
+		idents:   map[bson.ObjectID]models.Identity{},
+	}
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *Store) Close(ctx context.Context) error {
+	return nil
+}
+
+var _ storage.Store = (*Store)(nil)
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tokens[token.ID]; ok {
+		return storage.ErrDuplicate
+	}
+	s.tokens[token.ID] = *token
+	return nil
+}
+
+func (s *Store) GetRefreshToken(ctx context.Context, id string) (*models.RefreshToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	token, ok := s.tokens[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &token, nil
+}
+
+func (s *Store) RotateRefreshToken(ctx context.Context, id string, next *models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[id]
+	if !ok || token.RevokedAt != nil {
+		return storage.ErrNotFound
+	}
+	if _, ok := s.tokens[next.ID]; ok {
+		return storage.ErrDuplicate
+	}
+	now := time.Now()
+	token.RevokedAt = &now
+	token.ReplacedBy = next.ID
+	s.tokens[id] = token
+	s.tokens[next.ID] = *next
+	return nil
+}
+
+func (s *Store) RevokeRefreshToken(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[id]
+	if !ok || token.RevokedAt != nil {
+		return storage.ErrNotFound
+	}
+	now := time.Now()
+	token.RevokedAt = &now
+	s.tokens[id] = token
+	return nil
+}
+
+func (s *Store) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	s.revokeTokens(func(token models.RefreshToken) bool { return token.FamilyID == familyID })
+	return nil
+}
+
+func (s *Store) RevokeUserRefreshTokens(ctx context.Context, userID bson.ObjectID) error {
+	s.revokeTokens(func(token models.RefreshToken) bool { return token.UserID == userID })
+	return nil
+}
+
+func (s *Store) revokeTokens(match func(models.RefreshToken) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for id, token := range s.tokens {
+		if match(token) && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			s.tokens[id] = token
+		}
+	}
+}
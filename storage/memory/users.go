@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) CreateUser(ctx context.Context, user *models.Auth) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.users {
+		if existing.Email == user.Email || existing.Username == user.Username {
+			return storage.ErrDuplicate
+		}
+	}
+	user.ID = bson.NewObjectID()
+	saved := *user
+	saved.Roles = slices.Clone(user.Roles)
+	s.users[user.ID] = saved
+	return nil
+}
+
+func (s *Store) GetUserByID(ctx context.Context, id bson.ObjectID) (*models.Auth, error) {
+	return s.findUser(func(user models.Auth) bool { return user.ID == id })
+}
+
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (*models.Auth, error) {
+	return s.findUser(func(user models.Auth) bool { return user.Email == email })
+}
+
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (*models.Auth, error) {
+	return s.findUser(func(user models.Auth) bool { return user.Username == username })
+}
+
+func (s *Store) UpdatePassword(ctx context.Context, id bson.ObjectID, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	user.Password = passwordHash
+	user.UpdatedAt = time.Now()
+	s.users[id] = user
+	return nil
+}
+
+func (s *Store) MarkEmailVerified(ctx context.Context, id bson.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+	s.users[id] = user
+	return nil
+}
+
+func (s *Store) SetUserRoles(ctx context.Context, id bson.ObjectID, roles []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	user.Roles = slices.Clone(roles)
+	user.UpdatedAt = time.Now()
+	s.users[id] = user
+	return nil
+}
+
+func (s *Store) findUser(match func(models.Auth) bool) (*models.Auth, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, user := range s.users {
+		if match(user) {
+			return &user, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
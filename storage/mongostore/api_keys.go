@@ -0,0 +1,71 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func (s *Store) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	key.ID = bson.NewObjectID()
+	_, err := s.apiKeys.InsertOne(ctx, key)
+	if mongo.IsDuplicateKeyError(err) {
+		return storage.ErrDuplicate
+	}
+	return err
+}
+
+func (s *Store) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := s.apiKeys.FindOne(ctx, bson.M{"key_hash": keyHash}).Decode(&key); err != nil {
+		return nil, notFound(err)
+	}
+	return &key, nil
+}
+
+func (s *Store) ListAPIKeys(ctx context.Context, userID bson.ObjectID) ([]models.APIKey, error) {
+	cursor, err := s.apiKeys.Find(ctx, bson.M{"user_id": userID, "revoked_at": nil},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	var keys []models.APIKey
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *Store) TouchAPIKey(ctx context.Context, id bson.ObjectID, usedAt time.Time) error {
+	result, err := s.apiKeys.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_used_at": usedAt}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) RevokeAPIKey(ctx context.Context, userID, id bson.ObjectID) error {
+	result, err := s.apiKeys.UpdateOne(ctx, bson.M{"_id": id, "user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) RevokeUserAPIKeys(ctx context.Context, userID bson.ObjectID) error {
+	_, err := s.apiKeys.UpdateMany(ctx, bson.M{"user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	return err
+}
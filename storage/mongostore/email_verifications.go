@@ -0,0 +1,38 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func (s *Store) CreateEmailVerification(ctx context.Context, verification *models.EmailVerification) error {
+	_, err := s.verifies.InsertOne(ctx, verification)
+	if mongo.IsDuplicateKeyError(err) {
+		return storage.ErrDuplicate
+	}
+	return err
+}
+
+func (s *Store) ConsumeEmailVerification(ctx context.Context, id string) (*models.EmailVerification, error) {
+	now := time.Now()
+	var verification models.EmailVerification
+	err := s.verifies.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "used_at": nil, "expires_at": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"used_at": now}},
+	).Decode(&verification)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	verification.UsedAt = &now
+	// older links sent to the user are useless once the email is verified
+	_, err = s.verifies.UpdateMany(ctx, bson.M{"user_id": verification.UserID, "used_at": nil}, bson.M{"$set": bson.M{"used_at": now}})
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
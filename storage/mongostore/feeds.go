@@ -0,0 +1,195 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func (s *Store) CreateFeed(ctx context.Context, feed *models.Feed) error {
+	result, err := s.feeds.InsertOne(ctx, feed)
+	if err != nil {
+		return err
+	}
+	feed.ID, _ = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+func (s *Store) GetFeed(ctx context.Context, id bson.ObjectID) (*models.Feed, error) {
+	var feed models.Feed
+	if err := s.feeds.FindOne(ctx, bson.M{"_id": id}).Decode(&feed); err != nil {
+		return nil, notFound(err)
+	}
+	return &feed, nil
+}
+
+func (s *Store) GetFeedByURL(ctx context.Context, userID bson.ObjectID, url string) (*models.Feed, error) {
+	var feed models.Feed
+	if err := s.feeds.FindOne(ctx, bson.M{"user_id": userID, "url": url}).Decode(&feed); err != nil {
+		return nil, notFound(err)
+	}
+	return &feed, nil
+}
+
+func (s *Store) FindFeedByURL(ctx context.Context, url string) (*models.Feed, error) {
+	var feed models.Feed
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	if err := s.feeds.FindOne(ctx, bson.M{"url": url}, opts).Decode(&feed); err != nil {
+		return nil, notFound(err)
+	}
+	return &feed, nil
+}
+
+func (s *Store) ListFeedsByUser(ctx context.Context, userID bson.ObjectID) ([]models.Feed, error) {
+	return s.findFeeds(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+}
+
+func (s *Store) DeleteFeed(ctx context.Context, id bson.ObjectID) error {
+	result, err := s.feeds.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return storage.ErrNotFound
+	}
+	// the follows and posts of the feed go with it, and the states of the posts
+	if _, err := s.follows.DeleteMany(ctx, bson.M{"feed_id": id}); err != nil {
+		return err
+	}
+	if _, err := s.posts.DeleteMany(ctx, bson.M{"feed_id": id}); err != nil {
+		return err
+	}
+	_, err = s.states.DeleteMany(ctx, bson.M{"feed_id": id})
+	return err
+}
+
+func (s *Store) NextFeedsToFetch(ctx context.Context, limit int) ([]models.Feed, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "last_fetched_at", Value: 1}}).
+		SetLimit(int64(limit))
+	return s.findFeeds(ctx, bson.M{}, opts)
+}
+
+func (s *Store) MarkFeedFetched(ctx context.Context, id bson.ObjectID, status int, etag, lastModified string) error {
+	now := time.Now()
+	_, err := s.feeds.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"last_fetched_at": now,
+		"last_status":     status,
+		"etag":            etag,
+		"last_modified":   lastModified,
+		"error_count":     0,
+		"updated_at":      now,
+	}})
+	return err
+}
+
+func (s *Store) MarkFeedFailed(ctx context.Context, id bson.ObjectID, status int) error {
+	now := time.Now()
+	_, err := s.feeds.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"last_fetched_at": now, "last_status": status, "updated_at": now},
+		"$inc": bson.M{"error_count": 1},
+	})
+	return err
+}
+
+func (s *Store) FillFeedInfo(ctx context.Context, id bson.ObjectID, title, siteLink string) error {
+	if title != "" {
+		if _, err := s.feeds.UpdateOne(ctx, bson.M{"_id": id, "title": ""}, bson.M{"$set": bson.M{"title": title}}); err != nil {
+			return err
+		}
+	}
+	if siteLink != "" {
+		if _, err := s.feeds.UpdateOne(ctx, bson.M{"_id": id, "site_link": ""}, bson.M{"$set": bson.M{"site_link": siteLink}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) FollowFeed(ctx context.Context, userID, feedID bson.ObjectID) (*models.FeedFollow, error) {
+	follow := models.FeedFollow{
+		UserID:    userID,
+		FeedID:    feedID,
+		CreatedAt: time.Now(),
+	}
+	result, err := s.follows.InsertOne(ctx, follow)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			err = s.follows.FindOne(ctx, bson.M{"user_id": userID, "feed_id": feedID}).Decode(&follow)
+			return &follow, err
+		}
+		return nil, err
+	}
+	follow.ID, _ = result.InsertedID.(bson.ObjectID)
+	return &follow, nil
+}
+
+func (s *Store) ListFollows(ctx context.Context, userID bson.ObjectID) ([]models.FeedFollow, error) {
+	cursor, err := s.follows.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	follows := []models.FeedFollow{}
+	if err := cursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
+
+func (s *Store) DeleteFollow(ctx context.Context, userID, followID bson.ObjectID) error {
+	result, err := s.follows.DeleteOne(ctx, bson.M{"_id": followID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) IsFollowing(ctx context.Context, userID, feedID bson.ObjectID) (bool, error) {
+	count, err := s.follows.CountDocuments(ctx, bson.M{"user_id": userID, "feed_id": feedID})
+	return count > 0, err
+}
+
+func (s *Store) HasOtherFollowers(ctx context.Context, feedID, userID bson.ObjectID) (bool, error) {
+	count, err := s.follows.CountDocuments(ctx, bson.M{"feed_id": feedID, "user_id": bson.M{"$ne": userID}}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+func (s *Store) FollowedFeedIDs(ctx context.Context, userID bson.ObjectID) ([]bson.ObjectID, error) {
+	follows, err := s.ListFollows(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]bson.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		ids = append(ids, follow.FeedID)
+	}
+	return ids, nil
+}
+
+func (s *Store) ListFollowedFeeds(ctx context.Context, userID bson.ObjectID) ([]models.Feed, error) {
+	ids, err := s.FollowedFeedIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.findFeeds(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+}
+
+func (s *Store) findFeeds(ctx context.Context, filter bson.M, opts ...options.Lister[options.FindOptions]) ([]models.Feed, error) {
+	cursor, err := s.feeds.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	feeds := []models.Feed{}
+	if err := cursor.All(ctx, &feeds); err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
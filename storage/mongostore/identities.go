@@ -0,0 +1,27 @@
+package mongostore
+
+import (
+	"context"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func (s *Store) CreateIdentity(ctx context.Context, identity *models.Identity) error {
+	identity.ID = bson.NewObjectID()
+	_, err := s.idents.InsertOne(ctx, identity)
+	if mongo.IsDuplicateKeyError(err) {
+		return storage.ErrDuplicate
+	}
+	return err
+}
+
+func (s *Store) GetIdentity(ctx context.Context, issuer, subject string) (*models.Identity, error) {
+	var identity models.Identity
+	if err := s.idents.FindOne(ctx, bson.M{"issuer": issuer, "subject": subject}).Decode(&identity); err != nil {
+		return nil, notFound(err)
+	}
+	return &identity, nil
+}
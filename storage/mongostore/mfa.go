@@ -0,0 +1,68 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func (s *Store) GetMFA(ctx context.Context, userID bson.ObjectID) (*models.MFA, error) {
+	var mfa models.MFA
+	if err := s.mfa.FindOne(ctx, bson.M{"_id": userID}).Decode(&mfa); err != nil {
+		return nil, notFound(err)
+	}
+	return &mfa, nil
+}
+
+func (s *Store) SaveMFA(ctx context.Context, mfa *models.MFA) error {
+	_, err := s.mfa.ReplaceOne(ctx, bson.M{"_id": mfa.UserID}, mfa, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (s *Store) DeleteMFA(ctx context.Context, userID bson.ObjectID) error {
+	result, err := s.mfa.DeleteOne(ctx, bson.M{"_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) UseTOTPStep(ctx context.Context, userID bson.ObjectID, step int64) error {
+	return s.updateMFA(ctx, bson.M{"_id": userID, "last_step": bson.M{"$lt": step}}, bson.M{
+		"$set":   bson.M{"last_step": step, "failed_attempts": 0},
+		"$unset": bson.M{"last_failure_at": ""},
+	})
+}
+
+func (s *Store) UseRecoveryCode(ctx context.Context, userID bson.ObjectID, codeHash string) error {
+	return s.updateMFA(ctx, bson.M{"_id": userID, "recovery_codes": codeHash}, bson.M{
+		"$pull":  bson.M{"recovery_codes": codeHash},
+		"$set":   bson.M{"failed_attempts": 0},
+		"$unset": bson.M{"last_failure_at": ""},
+	})
+}
+
+func (s *Store) RecordMFAFailure(ctx context.Context, userID bson.ObjectID) error {
+	return s.updateMFA(ctx, bson.M{"_id": userID}, bson.M{
+		"$inc": bson.M{"failed_attempts": 1},
+		"$set": bson.M{"last_failure_at": time.Now()},
+	})
+}
+
+func (s *Store) updateMFA(ctx context.Context, filter, update bson.M) error {
+	result, err := s.mfa.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func (s *Store) CreatePasswordReset(ctx context.Context, reset *models.PasswordReset) error {
+	_, err := s.resets.InsertOne(ctx, reset)
+	if mongo.IsDuplicateKeyError(err) {
+		return storage.ErrDuplicate
+	}
+	return err
+}
+
+func (s *Store) ConsumePasswordReset(ctx context.Context, id string) (*models.PasswordReset, error) {
+	now := time.Now()
+	var reset models.PasswordReset
+	err := s.resets.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "used_at": nil, "expires_at": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"used_at": now}},
+	).Decode(&reset)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	reset.UsedAt = &now
+	// the other links sent to the user can not reset the password again
+	_, err = s.resets.UpdateMany(ctx, bson.M{"user_id": reset.UserID, "used_at": nil}, bson.M{"$set": bson.M{"used_at": now}})
+	if err != nil {
+		return nil, err
+	}
+	return &reset, nil
+}
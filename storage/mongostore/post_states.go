@@ -0,0 +1,149 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func (s *Store) SetPostsRead(ctx context.Context, userID bson.ObjectID, posts []models.Post, read bool) error {
+	now := time.Now()
+	set := bson.M{"read": read, "updated_at": now}
+	if read {
+		set["read_at"] = now
+	}
+	return s.upsertPostStates(ctx, userID, posts, set)
+}
+
+// MarkFeedRead writes the states from the posts collection with a $merge, the
+// posts never reach the application
+func (s *Store) MarkFeedRead(ctx context.Context, userID, feedID bson.ObjectID, before *time.Time) (int64, error) {
+	filter := bson.M{"feed_id": feedID}
+	if before != nil {
+		filter["published_at"] = bson.M{"$lte": *before}
+	}
+	count, err := s.posts.CountDocuments(ctx, filter)
+	if err != nil || count == 0 {
+		return count, err
+	}
+	now := time.Now()
+	cursor, err := s.posts.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$project", Value: bson.M{
+			"_id":        0,
+			"user_id":    userID,
+			"post_id":    "$_id",
+			"feed_id":    "$feed_id",
+			"read":       true,
+			"read_at":    now,
+			"starred":    false,
+			"updated_at": now,
+		}}},
+		{{Key: "$merge", Value: bson.M{
+			"into": s.states.Name(),
+			"on":   bson.A{"user_id", "post_id"},
+			"whenMatched": bson.A{bson.M{"$set": bson.M{
+				"read":       true,
+				"read_at":    "$$new.read_at",
+				"updated_at": "$$new.updated_at",
+			}}},
+			"whenNotMatched": "insert",
+		}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, cursor.Close(ctx)
+}
+
+func (s *Store) SetPostStarred(ctx context.Context, userID bson.ObjectID, post models.Post, starred bool) error {
+	now := time.Now()
+	set := bson.M{"starred": starred, "updated_at": now}
+	if starred {
+		set["starred_at"] = now
+	}
+	return s.upsertPostStates(ctx, userID, []models.Post{post}, set)
+}
+
+func (s *Store) upsertPostStates(ctx context.Context, userID bson.ObjectID, posts []models.Post, set bson.M) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	writes := make([]mongo.WriteModel, 0, len(posts))
+	for _, post := range posts {
+		setOnInsert := bson.M{}
+		if !post.FeedID.IsZero() {
+			setOnInsert["feed_id"] = post.FeedID
+		}
+		// the flag that is not set by this update starts as false
+		for _, flag := range []string{"read", "starred"} {
+			if _, ok := set[flag]; !ok {
+				setOnInsert[flag] = false
+			}
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"user_id": userID, "post_id": post.ID}).
+			SetUpdate(bson.M{"$set": set, "$setOnInsert": setOnInsert}).
+			SetUpsert(true))
+	}
+	_, err := s.states.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (s *Store) GetPostStates(ctx context.Context, userID bson.ObjectID, postIDs []bson.ObjectID) (map[bson.ObjectID]models.PostState, error) {
+	cursor, err := s.states.Find(ctx, bson.M{"user_id": userID, "post_id": bson.M{"$in": postIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var states []models.PostState
+	if err := cursor.All(ctx, &states); err != nil {
+		return nil, err
+	}
+	byPost := make(map[bson.ObjectID]models.PostState, len(states))
+	for _, state := range states {
+		byPost[state.PostID] = state
+	}
+	return byPost, nil
+}
+
+func (s *Store) CountUnreadPosts(ctx context.Context, userID bson.ObjectID, feedIDs []bson.ObjectID) (map[bson.ObjectID]int64, error) {
+	totals, err := countByFeed(ctx, s.posts, bson.M{"feed_id": bson.M{"$in": feedIDs}})
+	if err != nil {
+		return nil, err
+	}
+	read, err := countByFeed(ctx, s.states, bson.M{"user_id": userID, "read": true, "feed_id": bson.M{"$in": feedIDs}})
+	if err != nil {
+		return nil, err
+	}
+	unread := make(map[bson.ObjectID]int64, len(feedIDs))
+	for _, feedID := range feedIDs {
+		unread[feedID] = max(totals[feedID]-read[feedID], 0)
+	}
+	return unread, nil
+}
+
+func countByFeed(ctx context.Context, col *mongo.Collection, match bson.M) (map[bson.ObjectID]int64, error) {
+	cursor, err := col.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{"_id": "$feed_id", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		FeedID bson.ObjectID `bson:"_id"`
+		Count  int64         `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[bson.ObjectID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.FeedID] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,260 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func (s *Store) CreatePost(ctx context.Context, post *models.Post) error {
+	result, err := s.posts.InsertOne(ctx, post)
+	if err != nil {
+		return err
+	}
+	post.ID, _ = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+func (s *Store) GetPost(ctx context.Context, id bson.ObjectID) (*models.Post, error) {
+	var post models.Post
+	if err := s.posts.FindOne(ctx, bson.M{"_id": id}).Decode(&post); err != nil {
+		return nil, notFound(err)
+	}
+	return &post, nil
+}
+
+func (s *Store) GetVisiblePost(ctx context.Context, userID, id bson.ObjectID) (*models.Post, error) {
+	visible, err := s.visibleFilter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var post models.Post
+	if err := s.posts.FindOne(ctx, and(visible, bson.M{"_id": id})).Decode(&post); err != nil {
+		return nil, notFound(err)
+	}
+	return &post, nil
+}
+
+func (s *Store) UpdatePost(ctx context.Context, id bson.ObjectID, update storage.PostUpdate) error {
+	set := bson.M{"updated_at": time.Now()}
+	if update.Title != nil {
+		set["title"] = *update.Title
+	}
+	if update.Description != nil {
+		set["description"] = *update.Description
+	}
+	if update.Link != nil {
+		set["link"] = *update.Link
+	}
+	result, err := s.posts.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) DeletePost(ctx context.Context, id bson.ObjectID) error {
+	result, err := s.posts.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return storage.ErrNotFound
+	}
+	_, err = s.states.DeleteMany(ctx, bson.M{"post_id": id})
+	return err
+}
+
+func (s *Store) ListPosts(ctx context.Context, userID bson.ObjectID, query storage.PostListQuery) ([]models.Post, error) {
+	visible, err := s.visibleFilter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	conditions := append(postFilterConditions(query.PostFilter), visible)
+	op, direction := "$lt", -1
+	if query.Ascending {
+		op, direction = "$gt", 1
+	}
+	if query.After != nil {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"published_at": bson.M{op: query.After.PublishedAt}},
+			bson.M{"published_at": query.After.PublishedAt, "_id": bson.M{op: query.After.ID}},
+		}})
+	}
+	sort := bson.D{{Key: "published_at", Value: direction}, {Key: "_id", Value: direction}}
+	if !query.UnreadOnly && !query.StarredOnly {
+		opts := options.Find().SetSort(sort).SetLimit(int64(query.Limit))
+		return s.findPosts(ctx, bson.M{"$and": conditions}, opts)
+	}
+	// the state of every page candidate is joined in, collecting the ids of
+	// everything the user ever read would grow without bound
+	stateFilter := bson.M{"state.read": bson.M{"$ne": true}}
+	if query.StarredOnly {
+		stateFilter = bson.M{"state.starred": true}
+		if query.UnreadOnly {
+			stateFilter["state.read"] = bson.M{"$ne": true}
+		}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$and": conditions}}},
+		{{Key: "$sort", Value: sort}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": s.states.Name(),
+			"let":  bson.M{"post_id": "$_id"},
+			"pipeline": bson.A{bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+				bson.M{"$eq": bson.A{"$user_id", userID}},
+				bson.M{"$eq": bson.A{"$post_id", "$$post_id"}},
+			}}}}},
+			"as": "state",
+		}}},
+		{{Key: "$match", Value: stateFilter}},
+		{{Key: "$limit", Value: query.Limit}},
+		{{Key: "$project", Value: bson.M{"state": 0}}},
+	}
+	cursor, err := s.posts.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	posts := []models.Post{}
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (s *Store) SearchPosts(ctx context.Context, userID bson.ObjectID, query storage.PostSearchQuery) ([]storage.ScoredPost, error) {
+	visible, err := s.visibleFilter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// $text has to be a top level condition
+	filter := bson.M{
+		"$and":  append(postFilterConditions(query.PostFilter), visible),
+		"$text": bson.M{"$search": query.Text},
+	}
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "published_at", Value: -1}}).
+		SetSkip(int64(query.Offset)).
+		SetLimit(int64(query.Limit))
+	cursor, err := s.posts.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	results := []storage.ScoredPost{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *Store) GetVisiblePosts(ctx context.Context, userID bson.ObjectID, ids []bson.ObjectID) ([]models.Post, error) {
+	visible, err := s.visibleFilter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.findPosts(ctx, and(visible, bson.M{"_id": bson.M{"$in": ids}}))
+}
+
+func (s *Store) UpsertFeedPost(ctx context.Context, post models.Post) (inserted, updated bool, err error) {
+	post.ItemKey = storage.ItemKey(post)
+	post.ContentHash = storage.ContentHash(post)
+	now := time.Now()
+
+	// the content_hash condition makes the upsert miss unchanged items, the
+	// insert it then attempts is rejected by the unique index
+	filter := bson.M{
+		"feed_id":      post.FeedID,
+		"item_key":     post.ItemKey,
+		"content_hash": bson.M{"$ne": post.ContentHash},
+	}
+	set := bson.M{
+		"guid":         post.GUID,
+		"title":        post.Title,
+		"description":  post.Description,
+		"content":      post.Content,
+		"link":         post.Link,
+		"author":       post.Author,
+		"categories":   post.Categories,
+		"enclosure":    post.Enclosure,
+		"content_hash": post.ContentHash,
+		"updated_at":   now,
+	}
+	setOnInsert := bson.M{"created_at": now}
+	// undated items keep the time they were first seen
+	if post.PublishedAt.IsZero() {
+		setOnInsert["published_at"] = now
+	} else {
+		set["published_at"] = post.PublishedAt
+	}
+	update := bson.M{"$set": set, "$setOnInsert": setOnInsert}
+	result, err := s.posts.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	return result.UpsertedCount > 0, result.MatchedCount > 0, nil
+}
+
+// visibleFilter matches the posts of the feeds the user follows and the posts they created
+func (s *Store) visibleFilter(ctx context.Context, userID bson.ObjectID) (bson.M, error) {
+	feedIDs, err := s.FollowedFeedIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{"feed_id": bson.M{"$in": feedIDs}},
+		bson.M{"user_id": userID},
+	}}, nil
+}
+
+func postFilterConditions(f storage.PostFilter) bson.A {
+	conditions := bson.A{}
+	if f.FeedID != nil {
+		conditions = append(conditions, bson.M{"feed_id": *f.FeedID})
+	}
+	if f.Category != "" {
+		conditions = append(conditions, bson.M{"categories": f.Category})
+	}
+	if f.Author != "" {
+		conditions = append(conditions, bson.M{"author": f.Author})
+	}
+	if f.Since != nil {
+		conditions = append(conditions, bson.M{"published_at": bson.M{"$gte": *f.Since}})
+	}
+	if f.Until != nil {
+		conditions = append(conditions, bson.M{"published_at": bson.M{"$lt": *f.Until}})
+	}
+	return conditions
+}
+
+func and(conditions ...bson.M) bson.M {
+	all := make(bson.A, 0, len(conditions))
+	for _, condition := range conditions {
+		all = append(all, condition)
+	}
+	return bson.M{"$and": all}
+}
+
+func (s *Store) findPosts(ctx context.Context, filter bson.M, opts ...options.Lister[options.FindOptions]) ([]models.Post, error) {
+	cursor, err := s.posts.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	posts := []models.Post{}
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
@@ -0,0 +1,50 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) CreateProfiles(ctx context.Context, profiles []models.User) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+	result, err := s.profiles.InsertMany(ctx, profiles)
+	if err != nil {
+		return err
+	}
+	for i, id := range result.InsertedIDs {
+		profiles[i].ID, _ = id.(bson.ObjectID)
+	}
+	return nil
+}
+
+func (s *Store) FindProfilesByEmail(ctx context.Context, email string) ([]models.User, error) {
+	cursor, err := s.profiles.Find(ctx, bson.M{"email": email})
+	if err != nil {
+		return nil, err
+	}
+	profiles := []models.User{}
+	if err := cursor.All(ctx, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func (s *Store) UpdateProfile(ctx context.Context, id bson.ObjectID, update storage.ProfileUpdate) error {
+	result, err := s.profiles.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"name": update.Name, "updatedAt": time.Now()},
+		"$inc": bson.M{"age": update.AgeIncrement},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func (s *Store) CreateSession(ctx context.Context, session *models.Session) error {
+	session.ID = bson.NewObjectID()
+	_, err := s.sessions.InsertOne(ctx, session)
+	return err
+}
+
+func (s *Store) GetSession(ctx context.Context, id bson.ObjectID) (*models.Session, error) {
+	var session models.Session
+	if err := s.sessions.FindOne(ctx, bson.M{"_id": id}).Decode(&session); err != nil {
+		return nil, notFound(err)
+	}
+	return &session, nil
+}
+
+func (s *Store) ListActiveSessions(ctx context.Context, userID bson.ObjectID) ([]models.Session, error) {
+	filter := bson.M{"user_id": userID, "revoked_at": nil, "expires_at": bson.M{"$gt": time.Now()}}
+	cursor, err := s.sessions.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "last_used_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	var sessions []models.Session
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (s *Store) TouchSession(ctx context.Context, id bson.ObjectID, usedAt, expiresAt time.Time) error {
+	set := bson.M{"last_used_at": usedAt}
+	if !expiresAt.IsZero() {
+		set["expires_at"] = expiresAt
+	}
+	result, err := s.sessions.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) RevokeSession(ctx context.Context, id bson.ObjectID) error {
+	result, err := s.sessions.UpdateOne(ctx, bson.M{"_id": id, "revoked_at": nil}, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) RevokeUserSessions(ctx context.Context, userID bson.ObjectID) error {
+	_, err := s.sessions.UpdateMany(ctx, bson.M{"user_id": userID, "revoked_at": nil}, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	return err
+}
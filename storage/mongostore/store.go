@@ -0,0 +1,165 @@
+// Package mongostore implements the storage interfaces on MongoDB.
+package mongostore
+
+import (
+	"context"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+// Store implements storage.Store
+type Store struct {
+	client   *mongo.Client
+	users    *mongo.Collection
+	profiles *mongo.Collection
+	feeds    *mongo.Collection
+	follows  *mongo.Collection
+	posts    *mongo.Collection
+	states   *mongo.Collection
+	tokens   *mongo.Collection
+	sessions *mongo.Collection
+	resets   *mongo.Collection
+	verifies *mongo.Collection
+	mfa      *mongo.Collection
+	apiKeys  *mongo.Collection
+	idents   *mongo.Collection
+}
+
+// New returns a store on the collections of db and creates their indexes,
+// closing the store disconnects the client of db
+func New(ctx context.Context, db *mongo.Database) (*Store, error) {
+	s := &Store{
+		client:   db.Client(),
+		users:    db.Collection("auths"),
+		profiles: db.Collection("users"),
+		feeds:    db.Collection("feeds"),
+		follows:  db.Collection("feed_follows"),
+		posts:    db.Collection("posts"),
+		states:   db.Collection("post_states"),
+		tokens:   db.Collection("refresh_tokens"),
+		sessions: db.Collection("sessions"),
+		resets:   db.Collection("password_resets"),
+		verifies: db.Collection("email_verifications"),
+		mfa:      db.Collection("mfa"),
+		apiKeys:  db.Collection("api_keys"),
+		idents:   db.Collection("identities"),
+	}
+	if err := s.ensureIndexes(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) ensureIndexes(ctx context.Context) error {
+	indexes := map[*mongo.Collection][]mongo.IndexModel{
+		// CreateUser relies on them to report taken emails and usernames
+		s.users: {
+			{
+				Keys:    bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().SetName("email_unique").SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "username", Value: 1}},
+				Options: options.Index().SetName("username_unique").SetUnique(true),
+			},
+		},
+		// the unique (feed, item) index deduplicates aggregated posts, posts
+		// created by hand have no item key and are not indexed
+		s.posts: {
+			{
+				Keys: bson.D{{Key: "feed_id", Value: 1}, {Key: "item_key", Value: 1}},
+				Options: options.Index().
+					SetName("feed_item_unique").
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"item_key": bson.M{"$exists": true}}),
+			},
+			{
+				Keys:    bson.D{{Key: "published_at", Value: -1}, {Key: "_id", Value: -1}},
+				Options: options.Index().SetName("published_at_id"),
+			},
+			{
+				Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}, {Key: "content", Value: "text"}},
+				Options: options.Index().
+					SetName("posts_text").
+					SetWeights(bson.D{{Key: "title", Value: 10}, {Key: "description", Value: 3}, {Key: "content", Value: 1}}),
+			},
+		},
+		s.follows: {{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "feed_id", Value: 1}},
+			Options: options.Index().SetName("user_feed_unique").SetUnique(true),
+		}},
+		s.states: {{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "post_id", Value: 1}},
+			Options: options.Index().SetName("user_post_unique").SetUnique(true),
+		}},
+		s.tokens: {
+			{
+				Keys:    bson.D{{Key: "user_id", Value: 1}},
+				Options: options.Index().SetName("user_id"),
+			},
+			{
+				Keys:    bson.D{{Key: "family_id", Value: 1}},
+				Options: options.Index().SetName("family_id"),
+			},
+		},
+		s.sessions: {{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "last_used_at", Value: -1}},
+			Options: options.Index().SetName("user_last_used"),
+		}},
+		s.apiKeys: {
+			{
+				Keys:    bson.D{{Key: "key_hash", Value: 1}},
+				Options: options.Index().SetName("key_hash_unique").SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "user_id", Value: 1}},
+				Options: options.Index().SetName("user_id"),
+			},
+		},
+		s.idents: {{
+			Keys:    bson.D{{Key: "issuer", Value: 1}, {Key: "subject", Value: 1}},
+			Options: options.Index().SetName("issuer_subject_unique").SetUnique(true),
+		}},
+		// expired resets and verifications are useless, mongo deletes them
+		s.resets: {
+			{
+				Keys:    bson.D{{Key: "user_id", Value: 1}},
+				Options: options.Index().SetName("user_id"),
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+			},
+		},
+		s.verifies: {
+			{
+				Keys:    bson.D{{Key: "user_id", Value: 1}},
+				Options: options.Index().SetName("user_id"),
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+			},
+		},
+	}
+	for coll, specs := range indexes {
+		if _, err := coll.Indexes().CreateMany(ctx, specs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx, readpref.Primary())
+}
+
+func (s *Store) Close(ctx context.Context) error {
+	return s.client.Disconnect(ctx)
+}
+
+var _ storage.Store = (*Store)(nil)
@@ -0,0 +1,38 @@
+package mongostore
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/storagetest"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// TestStore needs a MongoDB server, its URI is read from MONGODB_TEST_URI.
+// Every test runs in its own database, dropped at the end.
+func TestStore(t *testing.T) {
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI is not set")
+	}
+	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+
+	storagetest.Run(t, func(t *testing.T) storage.Store {
+		ctx := context.Background()
+		db := client.Database("rss_test_" + bson.NewObjectID().Hex())
+		t.Cleanup(func() { db.Drop(ctx) })
+		s, err := New(ctx, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	})
+}
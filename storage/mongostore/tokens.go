@@ -0,0 +1,62 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func (s *Store) SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	_, err := s.tokens.InsertOne(ctx, token)
+	if mongo.IsDuplicateKeyError(err) {
+		return storage.ErrDuplicate
+	}
+	return err
+}
+
+func (s *Store) GetRefreshToken(ctx context.Context, id string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := s.tokens.FindOne(ctx, bson.M{"_id": id}).Decode(&token); err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (s *Store) RotateRefreshToken(ctx context.Context, id string, next *models.RefreshToken) error {
+	result, err := s.tokens.UpdateOne(ctx, bson.M{"_id": id, "revoked_at": nil}, bson.M{"$set": bson.M{
+		"revoked_at":  time.Now(),
+		"replaced_by": next.ID,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return s.SaveRefreshToken(ctx, next)
+}
+
+func (s *Store) RevokeRefreshToken(ctx context.Context, id string) error {
+	result, err := s.tokens.UpdateOne(ctx, bson.M{"_id": id, "revoked_at": nil}, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, err := s.tokens.UpdateMany(ctx, bson.M{"family_id": familyID, "revoked_at": nil}, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	return err
+}
+
+func (s *Store) RevokeUserRefreshTokens(ctx context.Context, userID bson.ObjectID) error {
+	_, err := s.tokens.UpdateMany(ctx, bson.M{"user_id": userID, "revoked_at": nil}, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	return err
+}
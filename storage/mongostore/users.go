@@ -0,0 +1,95 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func (s *Store) CreateUser(ctx context.Context, user *models.Auth) error {
+	result, err := s.users.InsertOne(ctx, user)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return storage.ErrDuplicate
+		}
+		return err
+	}
+	user.ID, _ = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+func (s *Store) GetUserByID(ctx context.Context, id bson.ObjectID) (*models.Auth, error) {
+	return s.findUser(ctx, bson.M{"_id": id})
+}
+
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (*models.Auth, error) {
+	return s.findUser(ctx, bson.M{"email": email})
+}
+
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (*models.Auth, error) {
+	return s.findUser(ctx, bson.M{"username": username})
+}
+
+func (s *Store) UpdatePassword(ctx context.Context, id bson.ObjectID, passwordHash string) error {
+	result, err := s.users.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"password":   passwordHash,
+		"updated_at": time.Now(),
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) MarkEmailVerified(ctx context.Context, id bson.ObjectID) error {
+	now := time.Now()
+	result, err := s.users.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"email_verified":    true,
+		"email_verified_at": now,
+		"updated_at":        now,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) SetUserRoles(ctx context.Context, id bson.ObjectID, roles []string) error {
+	result, err := s.users.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"roles":      roles,
+		"updated_at": time.Now(),
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) findUser(ctx context.Context, filter bson.M) (*models.Auth, error) {
+	var user models.Auth
+	if err := s.users.FindOne(ctx, filter).Decode(&user); err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+// notFound translates the driver error of a missing document
+func notFound(err error) error {
+	if err == mongo.ErrNoDocuments {
+		return storage.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+)
+
+// TextQuery is a search text split in the words to find and the words
+// excluded with a leading "-", for the backends without a text index
+type TextQuery struct {
+	Words    []string
+	Excluded []string
+}
+
+func ParseTextQuery(text string) TextQuery {
+	var q TextQuery
+	for _, word := range strings.Fields(strings.ToLower(strings.ReplaceAll(text, `"`, " "))) {
+		if strings.HasPrefix(word, "-") {
+			if word = strings.TrimPrefix(word, "-"); word != "" {
+				q.Excluded = append(q.Excluded, word)
+			}
+		} else {
+			q.Words = append(q.Words, word)
+		}
+	}
+	return q
+}
+
+// Score weighs the post like the MongoDB text index: each word found in the
+// title counts 10, in the description 3 and in the content 1. A post with an
+// excluded word scores 0.
+func (q TextQuery) Score(post models.Post) float64 {
+	title, description, content := strings.ToLower(post.Title), strings.ToLower(post.Description), strings.ToLower(post.Content)
+	for _, word := range q.Excluded {
+		if strings.Contains(title, word) || strings.Contains(description, word) || strings.Contains(content, word) {
+			return 0
+		}
+	}
+	var score float64
+	for _, word := range q.Words {
+		score += 10*float64(strings.Count(title, word)) + 3*float64(strings.Count(description, word)) + float64(strings.Count(content, word))
+	}
+	return score
+}
+
+// SortScoredPosts orders search results by relevance then date, like the
+// MongoDB backend, and returns the requested page
+func SortScoredPosts(results []ScoredPost, offset, limit int) []ScoredPost {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].PublishedAt.After(results[j].PublishedAt)
+	})
+	if offset >= len(results) {
+		return []ScoredPost{}
+	}
+	results = results[offset:]
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,79 @@
+package sqlitestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func (s *Store) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	id := bson.NewObjectID()
+	_, err := s.db.ExecContext(ctx, `INSERT INTO api_keys (`+apiKeyColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		objectID(id), objectID(key.UserID), key.Name, key.Prefix, key.KeyHash, stringList(key.Scopes),
+		optionalTime(key.ExpiresAt), optionalTime(key.LastUsedAt), optionalTime(key.RevokedAt), timestamp(key.CreatedAt))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return storage.ErrDuplicate
+		}
+		return err
+	}
+	key.ID = id
+	return nil
+}
+
+func (s *Store) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ?`, keyHash))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return key, nil
+}
+
+func (s *Store) ListAPIKeys(ctx context.Context, userID bson.ObjectID) ([]models.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys
+		WHERE user_id = ? AND revoked_at IS NULL ORDER BY created_at DESC`, objectID(userID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+func (s *Store) TouchAPIKey(ctx context.Context, id bson.ObjectID, usedAt time.Time) error {
+	return affected(s.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, timestamp(usedAt), objectID(id)))
+}
+
+func (s *Store) RevokeAPIKey(ctx context.Context, userID, id bson.ObjectID) error {
+	return affected(s.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`,
+		timestamp(time.Now()), objectID(id), objectID(userID)))
+}
+
+func (s *Store) RevokeUserAPIKeys(ctx context.Context, userID bson.ObjectID) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`,
+		timestamp(time.Now()), objectID(userID))
+	return err
+}
+
+func scanAPIKey(row scanner) (*models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan((*objectID)(&key.ID), (*objectID)(&key.UserID), &key.Name, &key.Prefix, &key.KeyHash,
+		(*stringList)(&key.Scopes), nullTimestamp{&key.ExpiresAt}, nullTimestamp{&key.LastUsedAt},
+		nullTimestamp{&key.RevokedAt}, (*timestamp)(&key.CreatedAt))
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
@@ -0,0 +1,43 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+)
+
+func (s *Store) CreateEmailVerification(ctx context.Context, verification *models.EmailVerification) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO email_verifications (id, user_id, expires_at, used_at, created_at) VALUES (?, ?, ?, ?, ?)`,
+		verification.ID, objectID(verification.UserID), timestamp(verification.ExpiresAt),
+		optionalTime(verification.UsedAt), timestamp(verification.CreatedAt))
+	if isUniqueViolation(err) {
+		return storage.ErrDuplicate
+	}
+	return err
+}
+
+func (s *Store) ConsumeEmailVerification(ctx context.Context, id string) (*models.EmailVerification, error) {
+	var verification models.EmailVerification
+	now := time.Now()
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `SELECT id, user_id, expires_at, created_at FROM email_verifications
+			WHERE id = ? AND used_at IS NULL AND expires_at > ?`, id, timestamp(now)).Scan(
+			&verification.ID, (*objectID)(&verification.UserID),
+			(*timestamp)(&verification.ExpiresAt), (*timestamp)(&verification.CreatedAt))
+		if err != nil {
+			return notFound(err)
+		}
+		// older links sent to the user are useless once the email is verified
+		_, err = tx.ExecContext(ctx, `UPDATE email_verifications SET used_at = ? WHERE user_id = ? AND used_at IS NULL`,
+			timestamp(now), objectID(verification.UserID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	verification.UsedAt = &now
+	return &verification, nil
+}
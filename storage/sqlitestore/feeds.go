@@ -0,0 +1,193 @@
+package sqlitestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const feedColumns = `id, url, title, site_link, category, user_id, last_fetched_at, last_status,
+	etag, last_modified, error_count, created_at, updated_at`
+
+func (s *Store) CreateFeed(ctx context.Context, feed *models.Feed) error {
+	id := bson.NewObjectID()
+	_, err := s.db.ExecContext(ctx, `INSERT INTO feeds (`+feedColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		objectID(id), feed.URL, feed.Title, feed.SiteLink, feed.Category, objectID(feed.UserID),
+		optionalTime(feed.LastFetchedAt), feed.LastStatus, feed.ETag, feed.LastModified, feed.ErrorCount,
+		timestamp(feed.CreatedAt), timestamp(feed.UpdatedAt))
+	if err != nil {
+		return err
+	}
+	feed.ID = id
+	return nil
+}
+
+func (s *Store) GetFeed(ctx context.Context, id bson.ObjectID) (*models.Feed, error) {
+	return s.findFeed(ctx, `id = ?`, objectID(id))
+}
+
+func (s *Store) GetFeedByURL(ctx context.Context, userID bson.ObjectID, url string) (*models.Feed, error) {
+	return s.findFeed(ctx, `user_id = ? AND url = ?`, objectID(userID), url)
+}
+
+func (s *Store) FindFeedByURL(ctx context.Context, url string) (*models.Feed, error) {
+	return s.findFeed(ctx, `url = ? ORDER BY created_at LIMIT 1`, url)
+}
+
+func (s *Store) ListFeedsByUser(ctx context.Context, userID bson.ObjectID) ([]models.Feed, error) {
+	return s.findFeeds(ctx, `SELECT `+feedColumns+` FROM feeds WHERE user_id = ? ORDER BY created_at`, objectID(userID))
+}
+
+// DeleteFeed relies on the foreign keys to delete the follows and posts of the
+// feed, and the states of the posts
+func (s *Store) DeleteFeed(ctx context.Context, id bson.ObjectID) error {
+	return affected(s.db.ExecContext(ctx, `DELETE FROM feeds WHERE id = ?`, objectID(id)))
+}
+
+// NextFeedsToFetch relies on NULL sorting first for the feeds never fetched
+func (s *Store) NextFeedsToFetch(ctx context.Context, limit int) ([]models.Feed, error) {
+	return s.findFeeds(ctx, `SELECT `+feedColumns+` FROM feeds ORDER BY last_fetched_at LIMIT ?`, limit)
+}
+
+func (s *Store) MarkFeedFetched(ctx context.Context, id bson.ObjectID, status int, etag, lastModified string) error {
+	now := timestamp(time.Now())
+	_, err := s.db.ExecContext(ctx, `UPDATE feeds SET last_fetched_at = ?, last_status = ?, etag = ?, last_modified = ?,
+		error_count = 0, updated_at = ? WHERE id = ?`,
+		now, status, etag, lastModified, now, objectID(id))
+	return err
+}
+
+func (s *Store) MarkFeedFailed(ctx context.Context, id bson.ObjectID, status int) error {
+	now := timestamp(time.Now())
+	_, err := s.db.ExecContext(ctx, `UPDATE feeds SET last_fetched_at = ?, last_status = ?, error_count = error_count + 1,
+		updated_at = ? WHERE id = ?`,
+		now, status, now, objectID(id))
+	return err
+}
+
+func (s *Store) FillFeedInfo(ctx context.Context, id bson.ObjectID, title, siteLink string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE feeds SET
+		title = CASE WHEN title = '' THEN ? ELSE title END,
+		site_link = CASE WHEN site_link = '' THEN ? ELSE site_link END
+		WHERE id = ?`,
+		title, siteLink, objectID(id))
+	return err
+}
+
+func (s *Store) FollowFeed(ctx context.Context, userID, feedID bson.ObjectID) (*models.FeedFollow, error) {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO feed_follows (id, user_id, feed_id, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id, feed_id) DO NOTHING`,
+		objectID(bson.NewObjectID()), objectID(userID), objectID(feedID), timestamp(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	follows, err := s.findFollows(ctx, `SELECT id, user_id, feed_id, created_at FROM feed_follows WHERE user_id = ? AND feed_id = ?`,
+		objectID(userID), objectID(feedID))
+	if err != nil {
+		return nil, err
+	}
+	if len(follows) == 0 {
+		return nil, storage.ErrNotFound
+	}
+	return &follows[0], nil
+}
+
+func (s *Store) ListFollows(ctx context.Context, userID bson.ObjectID) ([]models.FeedFollow, error) {
+	return s.findFollows(ctx, `SELECT id, user_id, feed_id, created_at FROM feed_follows WHERE user_id = ? ORDER BY created_at`,
+		objectID(userID))
+}
+
+func (s *Store) DeleteFollow(ctx context.Context, userID, followID bson.ObjectID) error {
+	return affected(s.db.ExecContext(ctx, `DELETE FROM feed_follows WHERE id = ? AND user_id = ?`,
+		objectID(followID), objectID(userID)))
+}
+
+func (s *Store) IsFollowing(ctx context.Context, userID, feedID bson.ObjectID) (bool, error) {
+	var following bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM feed_follows WHERE user_id = ? AND feed_id = ?)`,
+		objectID(userID), objectID(feedID)).Scan(&following)
+	return following, err
+}
+
+func (s *Store) HasOtherFollowers(ctx context.Context, feedID, userID bson.ObjectID) (bool, error) {
+	var followed bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM feed_follows WHERE feed_id = ? AND user_id != ?)`,
+		objectID(feedID), objectID(userID)).Scan(&followed)
+	return followed, err
+}
+
+func (s *Store) FollowedFeedIDs(ctx context.Context, userID bson.ObjectID) ([]bson.ObjectID, error) {
+	follows, err := s.ListFollows(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]bson.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		ids = append(ids, follow.FeedID)
+	}
+	return ids, nil
+}
+
+func (s *Store) ListFollowedFeeds(ctx context.Context, userID bson.ObjectID) ([]models.Feed, error) {
+	return s.findFeeds(ctx, `SELECT `+feedColumns+` FROM feeds
+		WHERE id IN (SELECT feed_id FROM feed_follows WHERE user_id = ?) ORDER BY created_at`, objectID(userID))
+}
+
+func (s *Store) findFeed(ctx context.Context, condition string, args ...any) (*models.Feed, error) {
+	feed, err := scanFeed(s.db.QueryRowContext(ctx, `SELECT `+feedColumns+` FROM feeds WHERE `+condition, args...))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &feed, nil
+}
+
+func (s *Store) findFeeds(ctx context.Context, query string, args ...any) ([]models.Feed, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	feeds := []models.Feed{}
+	for rows.Next() {
+		feed, err := scanFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, feed)
+	}
+	return feeds, rows.Err()
+}
+
+// scanner is a *sql.Row or *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanFeed(row scanner) (models.Feed, error) {
+	var feed models.Feed
+	err := row.Scan((*objectID)(&feed.ID), &feed.URL, &feed.Title, &feed.SiteLink, &feed.Category, (*objectID)(&feed.UserID),
+		nullTimestamp{&feed.LastFetchedAt}, &feed.LastStatus, &feed.ETag, &feed.LastModified, &feed.ErrorCount,
+		(*timestamp)(&feed.CreatedAt), (*timestamp)(&feed.UpdatedAt))
+	return feed, err
+}
+
+func (s *Store) findFollows(ctx context.Context, query string, args ...any) ([]models.FeedFollow, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	follows := []models.FeedFollow{}
+	for rows.Next() {
+		var follow models.FeedFollow
+		if err := rows.Scan((*objectID)(&follow.ID), (*objectID)(&follow.UserID), (*objectID)(&follow.FeedID),
+			(*timestamp)(&follow.CreatedAt)); err != nil {
+			return nil, err
+		}
+		follows = append(follows, follow)
+	}
+	return follows, rows.Err()
+}
@@ -0,0 +1,36 @@
+package sqlitestore
+
+import (
+	"context"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const identityColumns = `id, user_id, issuer, subject, email, created_at`
+
+func (s *Store) CreateIdentity(ctx context.Context, identity *models.Identity) error {
+	id := bson.NewObjectID()
+	_, err := s.db.ExecContext(ctx, `INSERT INTO identities (`+identityColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		objectID(id), objectID(identity.UserID), identity.Issuer, identity.Subject, identity.Email, timestamp(identity.CreatedAt))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return storage.ErrDuplicate
+		}
+		return err
+	}
+	identity.ID = id
+	return nil
+}
+
+func (s *Store) GetIdentity(ctx context.Context, issuer, subject string) (*models.Identity, error) {
+	var identity models.Identity
+	err := s.db.QueryRowContext(ctx, `SELECT `+identityColumns+` FROM identities WHERE issuer = ? AND subject = ?`, issuer, subject).
+		Scan((*objectID)(&identity.ID), (*objectID)(&identity.UserID), &identity.Issuer, &identity.Subject, &identity.Email,
+			(*timestamp)(&identity.CreatedAt))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &identity, nil
+}
@@ -0,0 +1,50 @@
+package sqlitestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const mfaColumns = `user_id, secret, enabled, enabled_at, last_step, recovery_codes, failed_attempts, last_failure_at, created_at`
+
+func (s *Store) GetMFA(ctx context.Context, userID bson.ObjectID) (*models.MFA, error) {
+	var mfa models.MFA
+	err := s.db.QueryRowContext(ctx, `SELECT `+mfaColumns+` FROM mfa WHERE user_id = ?`, objectID(userID)).Scan(
+		(*objectID)(&mfa.UserID), &mfa.Secret, &mfa.Enabled, nullTimestamp{&mfa.EnabledAt}, &mfa.LastStep,
+		(*stringList)(&mfa.RecoveryCodes), &mfa.FailedAttempts, nullTimestamp{&mfa.LastFailureAt}, (*timestamp)(&mfa.CreatedAt))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &mfa, nil
+}
+
+func (s *Store) SaveMFA(ctx context.Context, mfa *models.MFA) error {
+	_, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO mfa (`+mfaColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		objectID(mfa.UserID), mfa.Secret, mfa.Enabled, optionalTime(mfa.EnabledAt), mfa.LastStep,
+		stringList(mfa.RecoveryCodes), mfa.FailedAttempts, optionalTime(mfa.LastFailureAt), timestamp(mfa.CreatedAt))
+	return err
+}
+
+func (s *Store) DeleteMFA(ctx context.Context, userID bson.ObjectID) error {
+	return affected(s.db.ExecContext(ctx, `DELETE FROM mfa WHERE user_id = ?`, objectID(userID)))
+}
+
+func (s *Store) UseTOTPStep(ctx context.Context, userID bson.ObjectID, step int64) error {
+	return affected(s.db.ExecContext(ctx, `UPDATE mfa SET last_step = ?, failed_attempts = 0, last_failure_at = NULL
+		WHERE user_id = ? AND last_step < ?`, step, objectID(userID), step))
+}
+
+func (s *Store) UseRecoveryCode(ctx context.Context, userID bson.ObjectID, codeHash string) error {
+	return affected(s.db.ExecContext(ctx, `UPDATE mfa SET failed_attempts = 0, last_failure_at = NULL,
+		recovery_codes = (SELECT json_group_array(value) FROM json_each(mfa.recovery_codes) WHERE value != ?)
+		WHERE user_id = ? AND EXISTS (SELECT 1 FROM json_each(mfa.recovery_codes) WHERE value = ?)`,
+		codeHash, objectID(userID), codeHash))
+}
+
+func (s *Store) RecordMFAFailure(ctx context.Context, userID bson.ObjectID) error {
+	return affected(s.db.ExecContext(ctx, `UPDATE mfa SET failed_attempts = failed_attempts + 1, last_failure_at = ? WHERE user_id = ?`,
+		timestamp(time.Now()), objectID(userID)))
+}
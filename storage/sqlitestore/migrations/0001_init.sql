@@ -0,0 +1,99 @@
+-- Ids are the hex strings of MongoDB object ids and times are UTC text in a
+-- fixed width layout, so both backends return the same values.
+
+CREATE TABLE auths (
+	id         TEXT PRIMARY KEY,
+	username   TEXT NOT NULL,
+	email      TEXT NOT NULL,
+	password   TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE UNIQUE INDEX auths_email ON auths (email);
+CREATE UNIQUE INDEX auths_username ON auths (username);
+
+-- profiles of the /users endpoints
+CREATE TABLE users (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	email      TEXT NOT NULL,
+	age        INTEGER NOT NULL,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX users_email ON users (email);
+
+CREATE TABLE feeds (
+	id              TEXT PRIMARY KEY,
+	url             TEXT NOT NULL,
+	title           TEXT NOT NULL DEFAULT '',
+	site_link       TEXT NOT NULL DEFAULT '',
+	category        TEXT NOT NULL DEFAULT '',
+	user_id         TEXT NOT NULL,
+	last_fetched_at TEXT,
+	last_status     INTEGER NOT NULL DEFAULT 0,
+	etag            TEXT NOT NULL DEFAULT '',
+	last_modified   TEXT NOT NULL DEFAULT '',
+	error_count     INTEGER NOT NULL DEFAULT 0,
+	created_at      TEXT NOT NULL,
+	updated_at      TEXT NOT NULL
+);
+CREATE INDEX feeds_user_url ON feeds (user_id, url);
+CREATE INDEX feeds_last_fetched_at ON feeds (last_fetched_at);
+
+CREATE TABLE feed_follows (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	feed_id    TEXT NOT NULL REFERENCES feeds (id) ON DELETE CASCADE,
+	created_at TEXT NOT NULL
+);
+CREATE UNIQUE INDEX feed_follows_user_feed ON feed_follows (user_id, feed_id);
+CREATE INDEX feed_follows_feed_id ON feed_follows (feed_id);
+
+-- posts created by hand have a user_id and no feed_id nor item_key
+CREATE TABLE posts (
+	id               TEXT PRIMARY KEY,
+	feed_id          TEXT REFERENCES feeds (id) ON DELETE CASCADE,
+	user_id          TEXT,
+	guid             TEXT NOT NULL DEFAULT '',
+	title            TEXT NOT NULL DEFAULT '',
+	description      TEXT NOT NULL DEFAULT '',
+	content          TEXT NOT NULL DEFAULT '',
+	link             TEXT NOT NULL DEFAULT '',
+	author           TEXT NOT NULL DEFAULT '',
+	categories       TEXT NOT NULL DEFAULT '[]',
+	enclosure_url    TEXT,
+	enclosure_type   TEXT NOT NULL DEFAULT '',
+	enclosure_length INTEGER NOT NULL DEFAULT 0,
+	published_at     TEXT NOT NULL,
+	item_key         TEXT,
+	content_hash     TEXT NOT NULL DEFAULT '',
+	created_at       TEXT NOT NULL,
+	updated_at       TEXT NOT NULL
+);
+CREATE UNIQUE INDEX posts_feed_item ON posts (feed_id, item_key) WHERE item_key IS NOT NULL;
+CREATE INDEX posts_published_at_id ON posts (published_at, id);
+CREATE INDEX posts_user_id ON posts (user_id);
+
+CREATE TABLE post_states (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	post_id    TEXT NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+	feed_id    TEXT,
+	read       INTEGER NOT NULL DEFAULT 0,
+	read_at    TEXT,
+	starred    INTEGER NOT NULL DEFAULT 0,
+	starred_at TEXT,
+	updated_at TEXT NOT NULL
+);
+CREATE UNIQUE INDEX post_states_user_post ON post_states (user_id, post_id);
+CREATE INDEX post_states_post_id ON post_states (post_id);
+
+CREATE TABLE refresh_tokens (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	expires_at TEXT NOT NULL,
+	revoked_at TEXT,
+	created_at TEXT NOT NULL
+);
+CREATE INDEX refresh_tokens_user_id ON refresh_tokens (user_id);
@@ -0,0 +1,3 @@
+ALTER TABLE refresh_tokens ADD COLUMN family_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE refresh_tokens ADD COLUMN replaced_by TEXT NOT NULL DEFAULT '';
+CREATE INDEX refresh_tokens_family_id ON refresh_tokens (family_id);
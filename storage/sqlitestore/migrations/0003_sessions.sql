@@ -0,0 +1,11 @@
+CREATE TABLE sessions (
+	id           TEXT PRIMARY KEY,
+	user_id      TEXT NOT NULL,
+	user_agent   TEXT NOT NULL,
+	ip           TEXT NOT NULL,
+	created_at   TEXT NOT NULL,
+	last_used_at TEXT NOT NULL,
+	expires_at   TEXT NOT NULL,
+	revoked_at   TEXT
+);
+CREATE INDEX sessions_user_id ON sessions (user_id, last_used_at);
@@ -0,0 +1,8 @@
+CREATE TABLE password_resets (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	expires_at TEXT NOT NULL,
+	used_at    TEXT,
+	created_at TEXT NOT NULL
+);
+CREATE INDEX password_resets_user_id ON password_resets (user_id);
@@ -0,0 +1,11 @@
+ALTER TABLE auths ADD COLUMN email_verified INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE auths ADD COLUMN email_verified_at TEXT;
+
+CREATE TABLE email_verifications (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	expires_at TEXT NOT NULL,
+	used_at    TEXT,
+	created_at TEXT NOT NULL
+);
+CREATE INDEX email_verifications_user_id ON email_verifications (user_id);
@@ -0,0 +1,12 @@
+-- second factors, recovery_codes is a JSON array of SHA-256 hashes
+CREATE TABLE mfa (
+	user_id         TEXT PRIMARY KEY,
+	secret          TEXT NOT NULL,
+	enabled         INTEGER NOT NULL,
+	enabled_at      TEXT,
+	last_step       INTEGER NOT NULL,
+	recovery_codes  TEXT NOT NULL,
+	failed_attempts INTEGER NOT NULL,
+	last_failure_at TEXT,
+	created_at      TEXT NOT NULL
+);
@@ -0,0 +1,15 @@
+-- scopes is a JSON array
+CREATE TABLE api_keys (
+	id           TEXT PRIMARY KEY,
+	user_id      TEXT NOT NULL,
+	name         TEXT NOT NULL,
+	prefix       TEXT NOT NULL,
+	key_hash     TEXT NOT NULL,
+	scopes       TEXT NOT NULL,
+	expires_at   TEXT,
+	last_used_at TEXT,
+	revoked_at   TEXT,
+	created_at   TEXT NOT NULL
+);
+CREATE UNIQUE INDEX api_keys_key_hash ON api_keys (key_hash);
+CREATE INDEX api_keys_user_id ON api_keys (user_id, created_at);
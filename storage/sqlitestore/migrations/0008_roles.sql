@@ -0,0 +1,2 @@
+-- JSON array, users created before roles have none and are plain users
+ALTER TABLE auths ADD COLUMN roles TEXT NOT NULL DEFAULT '[]';
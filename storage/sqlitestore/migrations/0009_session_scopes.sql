@@ -0,0 +1,2 @@
+-- JSON array, sessions opened before scopes have none
+ALTER TABLE sessions ADD COLUMN scopes TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,11 @@
+-- accounts of the users at OpenID Connect providers
+CREATE TABLE identities (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	issuer     TEXT NOT NULL,
+	subject    TEXT NOT NULL,
+	email      TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+CREATE UNIQUE INDEX identities_issuer_subject ON identities (issuer, subject);
+CREATE INDEX identities_user_id ON identities (user_id);
@@ -0,0 +1,41 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+)
+
+func (s *Store) CreatePasswordReset(ctx context.Context, reset *models.PasswordReset) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO password_resets (id, user_id, expires_at, used_at, created_at) VALUES (?, ?, ?, ?, ?)`,
+		reset.ID, objectID(reset.UserID), timestamp(reset.ExpiresAt), optionalTime(reset.UsedAt), timestamp(reset.CreatedAt))
+	if isUniqueViolation(err) {
+		return storage.ErrDuplicate
+	}
+	return err
+}
+
+func (s *Store) ConsumePasswordReset(ctx context.Context, id string) (*models.PasswordReset, error) {
+	var reset models.PasswordReset
+	now := time.Now()
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `SELECT id, user_id, expires_at, created_at FROM password_resets
+			WHERE id = ? AND used_at IS NULL AND expires_at > ?`, id, timestamp(now)).Scan(
+			&reset.ID, (*objectID)(&reset.UserID), (*timestamp)(&reset.ExpiresAt), (*timestamp)(&reset.CreatedAt))
+		if err != nil {
+			return notFound(err)
+		}
+		// the other links sent to the user can not reset the password again
+		_, err = tx.ExecContext(ctx, `UPDATE password_resets SET used_at = ? WHERE user_id = ? AND used_at IS NULL`,
+			timestamp(now), objectID(reset.UserID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	reset.UsedAt = &now
+	return &reset, nil
+}
@@ -0,0 +1,124 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) SetPostsRead(ctx context.Context, userID bson.ObjectID, posts []models.Post, read bool) error {
+	return s.upsertPostStates(ctx, userID, posts, "read", read)
+}
+
+func (s *Store) SetPostStarred(ctx context.Context, userID bson.ObjectID, post models.Post, starred bool) error {
+	return s.upsertPostStates(ctx, userID, []models.Post{post}, "starred", starred)
+}
+
+// MarkFeedRead upserts the states of the posts in one statement, the ids of
+// the new states are random 12 bytes like object ids
+func (s *Store) MarkFeedRead(ctx context.Context, userID, feedID bson.ObjectID, before *time.Time) (int64, error) {
+	now := timestamp(time.Now())
+	w := where{}
+	w.add(`p.feed_id = ?`, objectID(feedID))
+	if before != nil {
+		w.add(`p.published_at <= ?`, timestamp(*before))
+	}
+	args := append([]any{objectID(userID), now, now}, w.args...)
+	result, err := s.db.ExecContext(ctx, `INSERT INTO post_states (id, user_id, post_id, feed_id, read, read_at, updated_at)
+		SELECT lower(hex(randomblob(12))), ?, p.id, p.feed_id, 1, ?, ? FROM posts p`+w.String()+`
+		ON CONFLICT (user_id, post_id) DO UPDATE SET
+			read = 1,
+			read_at = excluded.read_at,
+			updated_at = excluded.updated_at`, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// upsertPostStates sets flag, which is "read" or "starred", on the states of
+// the posts. The date of the flag is kept when it is cleared, like the other
+// backends do.
+func (s *Store) upsertPostStates(ctx context.Context, userID bson.ObjectID, posts []models.Post, flag string, value bool) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	now := time.Now()
+	var flaggedAt *time.Time
+	if value {
+		flaggedAt = &now
+	}
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, post := range posts {
+			_, err := tx.ExecContext(ctx, `INSERT INTO post_states (id, user_id, post_id, feed_id, `+flag+`, `+flag+`_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (user_id, post_id) DO UPDATE SET
+					`+flag+` = excluded.`+flag+`,
+					`+flag+`_at = COALESCE(excluded.`+flag+`_at, post_states.`+flag+`_at),
+					updated_at = excluded.updated_at`,
+				objectID(bson.NewObjectID()), objectID(userID), objectID(post.ID), objectID(post.FeedID),
+				value, optionalTime(flaggedAt), timestamp(now))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) GetPostStates(ctx context.Context, userID bson.ObjectID, postIDs []bson.ObjectID) (map[bson.ObjectID]models.PostState, error) {
+	states := make(map[bson.ObjectID]models.PostState, len(postIDs))
+	if len(postIDs) == 0 {
+		return states, nil
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, post_id, feed_id, read, read_at, starred, starred_at, updated_at
+		FROM post_states WHERE user_id = ? AND post_id IN (`+placeholders(len(postIDs))+`)`,
+		append([]any{objectID(userID)}, objectIDs(postIDs)...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var state models.PostState
+		if err := rows.Scan((*objectID)(&state.ID), (*objectID)(&state.UserID), (*objectID)(&state.PostID), (*objectID)(&state.FeedID),
+			&state.Read, nullTimestamp{&state.ReadAt}, &state.Starred, nullTimestamp{&state.StarredAt},
+			(*timestamp)(&state.UpdatedAt)); err != nil {
+			return nil, err
+		}
+		states[state.PostID] = state
+	}
+	return states, rows.Err()
+}
+
+func (s *Store) CountUnreadPosts(ctx context.Context, userID bson.ObjectID, feedIDs []bson.ObjectID) (map[bson.ObjectID]int64, error) {
+	unread := make(map[bson.ObjectID]int64, len(feedIDs))
+	if len(feedIDs) == 0 {
+		return unread, nil
+	}
+	for _, feedID := range feedIDs {
+		unread[feedID] = 0
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT p.feed_id, COUNT(*) FROM posts p
+		WHERE p.feed_id IN (`+placeholders(len(feedIDs))+`)
+		AND NOT EXISTS (SELECT 1 FROM post_states s WHERE s.user_id = ? AND s.post_id = p.id AND s.read)
+		GROUP BY p.feed_id`,
+		append(objectIDs(feedIDs), objectID(userID))...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			feedID objectID
+			count  int64
+		)
+		if err := rows.Scan(&feedID, &count); err != nil {
+			return nil, err
+		}
+		unread[bson.ObjectID(feedID)] = count
+	}
+	return unread, rows.Err()
+}
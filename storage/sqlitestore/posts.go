@@ -0,0 +1,264 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const postColumns = `p.id, p.feed_id, p.user_id, p.guid, p.title, p.description, p.content, p.link, p.author,
+	p.categories, p.enclosure_url, p.enclosure_type, p.enclosure_length, p.published_at, p.item_key,
+	p.content_hash, p.created_at, p.updated_at`
+
+func (s *Store) CreatePost(ctx context.Context, post *models.Post) error {
+	post.ID = bson.NewObjectID()
+	if err := insertPost(ctx, s.db, *post); err != nil {
+		post.ID = bson.ObjectID{}
+		return err
+	}
+	return nil
+}
+
+func (s *Store) GetPost(ctx context.Context, id bson.ObjectID) (*models.Post, error) {
+	return s.findPost(ctx, where{conditions: []string{`p.id = ?`}, args: []any{objectID(id)}})
+}
+
+func (s *Store) GetVisiblePost(ctx context.Context, userID, id bson.ObjectID) (*models.Post, error) {
+	w := visibleTo(userID)
+	w.add(`p.id = ?`, objectID(id))
+	return s.findPost(ctx, w)
+}
+
+func (s *Store) UpdatePost(ctx context.Context, id bson.ObjectID, update storage.PostUpdate) error {
+	set := []string{`updated_at = ?`}
+	args := []any{timestamp(time.Now())}
+	for _, field := range []struct {
+		column string
+		value  *string
+	}{{"title", update.Title}, {"description", update.Description}, {"link", update.Link}} {
+		if field.value != nil {
+			set = append(set, field.column+` = ?`)
+			args = append(args, *field.value)
+		}
+	}
+	args = append(args, objectID(id))
+	return affected(s.db.ExecContext(ctx, `UPDATE posts SET `+strings.Join(set, ", ")+` WHERE id = ?`, args...))
+}
+
+// DeletePost relies on the foreign keys to delete the states of the post
+func (s *Store) DeletePost(ctx context.Context, id bson.ObjectID) error {
+	return affected(s.db.ExecContext(ctx, `DELETE FROM posts WHERE id = ?`, objectID(id)))
+}
+
+func (s *Store) ListPosts(ctx context.Context, userID bson.ObjectID, query storage.PostListQuery) ([]models.Post, error) {
+	w := visibleTo(userID)
+	addPostFilter(&w, query.PostFilter)
+	if query.UnreadOnly {
+		w.add(`NOT EXISTS (SELECT 1 FROM post_states s WHERE s.user_id = ? AND s.post_id = p.id AND s.read)`, objectID(userID))
+	}
+	if query.StarredOnly {
+		w.add(`EXISTS (SELECT 1 FROM post_states s WHERE s.user_id = ? AND s.post_id = p.id AND s.starred)`, objectID(userID))
+	}
+	op, direction := "<", "DESC"
+	if query.Ascending {
+		op, direction = ">", "ASC"
+	}
+	// hex ids sort like the bytes of the ids
+	if query.After != nil {
+		after := timestamp(query.After.PublishedAt)
+		w.add(`(p.published_at `+op+` ? OR (p.published_at = ? AND p.id `+op+` ?))`, after, after, objectID(query.After.ID))
+	}
+	return s.findPosts(ctx, `SELECT `+postColumns+` FROM posts p`+w.String()+
+		` ORDER BY p.published_at `+direction+`, p.id `+direction+` LIMIT ?`, append(w.args, query.Limit)...)
+}
+
+// SearchPosts narrows the posts down to the ones containing a word of the
+// query, then scores them with storage.TextQuery
+func (s *Store) SearchPosts(ctx context.Context, userID bson.ObjectID, query storage.PostSearchQuery) ([]storage.ScoredPost, error) {
+	text := storage.ParseTextQuery(query.Text)
+	if len(text.Words) == 0 {
+		return []storage.ScoredPost{}, nil
+	}
+	w := visibleTo(userID)
+	addPostFilter(&w, query.PostFilter)
+	matches := make([]string, 0, len(text.Words))
+	for _, word := range text.Words {
+		pattern := "%" + escapeLike(word) + "%"
+		matches = append(matches, `p.title LIKE ? ESCAPE '\' OR p.description LIKE ? ESCAPE '\' OR p.content LIKE ? ESCAPE '\'`)
+		w.args = append(w.args, pattern, pattern, pattern)
+	}
+	w.conditions = append(w.conditions, "("+strings.Join(matches, " OR ")+")")
+	posts, err := s.findPosts(ctx, `SELECT `+postColumns+` FROM posts p`+w.String(), w.args...)
+	if err != nil {
+		return nil, err
+	}
+	results := []storage.ScoredPost{}
+	for _, post := range posts {
+		if score := text.Score(post); score > 0 {
+			results = append(results, storage.ScoredPost{Post: post, Score: score})
+		}
+	}
+	return storage.SortScoredPosts(results, query.Offset, query.Limit), nil
+}
+
+func (s *Store) GetVisiblePosts(ctx context.Context, userID bson.ObjectID, ids []bson.ObjectID) ([]models.Post, error) {
+	if len(ids) == 0 {
+		return []models.Post{}, nil
+	}
+	w := visibleTo(userID)
+	w.add(`p.id IN (`+placeholders(len(ids))+`)`, objectIDs(ids)...)
+	return s.findPosts(ctx, `SELECT `+postColumns+` FROM posts p`+w.String(), w.args...)
+}
+
+// UpsertFeedPost looks the item up and writes it in the same transaction, the
+// transaction holds the write lock from its start so two scrapes of the same
+// feed cannot both insert the item
+func (s *Store) UpsertFeedPost(ctx context.Context, post models.Post) (inserted, updated bool, err error) {
+	post.ItemKey = storage.ItemKey(post)
+	post.ContentHash = storage.ContentHash(post)
+	now := time.Now()
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		var existing struct {
+			id          objectID
+			contentHash string
+			publishedAt timestamp
+			createdAt   timestamp
+		}
+		err := tx.QueryRowContext(ctx, `SELECT id, content_hash, published_at, created_at FROM posts WHERE feed_id = ? AND item_key = ?`,
+			objectID(post.FeedID), post.ItemKey).Scan(&existing.id, &existing.contentHash, &existing.publishedAt, &existing.createdAt)
+		if err == sql.ErrNoRows {
+			post.ID = bson.NewObjectID()
+			post.CreatedAt, post.UpdatedAt = now, now
+			// undated items keep the time they were first seen
+			if post.PublishedAt.IsZero() {
+				post.PublishedAt = now
+			}
+			inserted = true
+			return insertPost(ctx, tx, post)
+		}
+		if err != nil || existing.contentHash == post.ContentHash {
+			return err
+		}
+		if post.PublishedAt.IsZero() {
+			post.PublishedAt = time.Time(existing.publishedAt)
+		}
+		enclosureURL, enclosureType, enclosureLength := enclosureValues(post.Enclosure)
+		_, err = tx.ExecContext(ctx, `UPDATE posts SET guid = ?, title = ?, description = ?, content = ?, link = ?, author = ?,
+			categories = ?, enclosure_url = ?, enclosure_type = ?, enclosure_length = ?, published_at = ?,
+			content_hash = ?, updated_at = ? WHERE id = ?`,
+			post.GUID, post.Title, post.Description, post.Content, post.Link, post.Author,
+			stringList(post.Categories), enclosureURL, enclosureType, enclosureLength, timestamp(post.PublishedAt),
+			post.ContentHash, timestamp(now), existing.id)
+		updated = err == nil
+		return err
+	})
+	if err != nil {
+		return false, false, err
+	}
+	return inserted, updated, nil
+}
+
+// execer is a *sql.DB or *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertPost(ctx context.Context, db execer, post models.Post) error {
+	enclosureURL, enclosureType, enclosureLength := enclosureValues(post.Enclosure)
+	_, err := db.ExecContext(ctx, `INSERT INTO posts (id, feed_id, user_id, guid, title, description, content, link, author,
+		categories, enclosure_url, enclosure_type, enclosure_length, published_at, item_key, content_hash, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		objectID(post.ID), objectID(post.FeedID), objectID(post.UserID), post.GUID, post.Title, post.Description,
+		post.Content, post.Link, post.Author, stringList(post.Categories), enclosureURL, enclosureType, enclosureLength,
+		timestamp(post.PublishedAt), nullString(post.ItemKey), post.ContentHash, timestamp(post.CreatedAt), timestamp(post.UpdatedAt))
+	return err
+}
+
+func enclosureValues(enclosure *models.Enclosure) (url nullString, mimeType string, length int64) {
+	if enclosure == nil {
+		return "", "", 0
+	}
+	return nullString(enclosure.URL), enclosure.Type, enclosure.Length
+}
+
+// visibleTo matches the posts of the feeds the user follows and the posts they created
+func visibleTo(userID bson.ObjectID) where {
+	w := where{}
+	w.add(`(p.feed_id IN (SELECT feed_id FROM feed_follows WHERE user_id = ?) OR p.user_id = ?)`, objectID(userID), objectID(userID))
+	return w
+}
+
+func addPostFilter(w *where, f storage.PostFilter) {
+	if f.FeedID != nil {
+		w.add(`p.feed_id = ?`, objectID(*f.FeedID))
+	}
+	if f.Category != "" {
+		w.add(`EXISTS (SELECT 1 FROM json_each(p.categories) WHERE json_each.value = ?)`, f.Category)
+	}
+	if f.Author != "" {
+		w.add(`p.author = ?`, f.Author)
+	}
+	if f.Since != nil {
+		w.add(`p.published_at >= ?`, timestamp(*f.Since))
+	}
+	if f.Until != nil {
+		w.add(`p.published_at < ?`, timestamp(*f.Until))
+	}
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, with \ as escape character
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func (s *Store) findPost(ctx context.Context, w where) (*models.Post, error) {
+	post, err := scanPost(s.db.QueryRowContext(ctx, `SELECT `+postColumns+` FROM posts p`+w.String(), w.args...))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &post, nil
+}
+
+func (s *Store) findPosts(ctx context.Context, query string, args ...any) ([]models.Post, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	posts := []models.Post{}
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	return posts, rows.Err()
+}
+
+func scanPost(row scanner) (models.Post, error) {
+	var (
+		post         models.Post
+		itemKey      nullString
+		enclosureURL nullString
+		enclosure    models.Enclosure
+	)
+	err := row.Scan((*objectID)(&post.ID), (*objectID)(&post.FeedID), (*objectID)(&post.UserID), &post.GUID, &post.Title,
+		&post.Description, &post.Content, &post.Link, &post.Author, (*stringList)(&post.Categories),
+		&enclosureURL, &enclosure.Type, &enclosure.Length, (*timestamp)(&post.PublishedAt), &itemKey,
+		&post.ContentHash, (*timestamp)(&post.CreatedAt), (*timestamp)(&post.UpdatedAt))
+	if err != nil {
+		return post, err
+	}
+	post.ItemKey = string(itemKey)
+	if enclosureURL != "" {
+		enclosure.URL = string(enclosureURL)
+		post.Enclosure = &enclosure
+	}
+	return post, nil
+}
@@ -0,0 +1,56 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Store) CreateProfiles(ctx context.Context, profiles []models.User) error {
+	ids := make([]bson.ObjectID, len(profiles))
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		for i, profile := range profiles {
+			ids[i] = bson.NewObjectID()
+			_, err := tx.ExecContext(ctx, `INSERT INTO users (id, name, email, age, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+				objectID(ids[i]), profile.Name, profile.Email, profile.Age, timestamp(profile.CreatedAt), timestamp(profile.UpdatedAt))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := range profiles {
+		profiles[i].ID = ids[i]
+	}
+	return nil
+}
+
+func (s *Store) FindProfilesByEmail(ctx context.Context, email string) ([]models.User, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, email, age, created_at, updated_at FROM users WHERE email = ?`, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	profiles := []models.User{}
+	for rows.Next() {
+		var profile models.User
+		if err := rows.Scan((*objectID)(&profile.ID), &profile.Name, &profile.Email, &profile.Age,
+			(*timestamp)(&profile.CreatedAt), (*timestamp)(&profile.UpdatedAt)); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
+
+func (s *Store) UpdateProfile(ctx context.Context, id bson.ObjectID, update storage.ProfileUpdate) error {
+	return affected(s.db.ExecContext(ctx, `UPDATE users SET name = ?, age = age + ?, updated_at = ? WHERE id = ?`,
+		update.Name, update.AgeIncrement, timestamp(time.Now()), objectID(id)))
+}
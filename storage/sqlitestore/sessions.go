@@ -0,0 +1,81 @@
+package sqlitestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const sessionColumns = `id, user_id, user_agent, ip, scopes, created_at, last_used_at, expires_at, revoked_at`
+
+func (s *Store) CreateSession(ctx context.Context, session *models.Session) error {
+	id := bson.NewObjectID()
+	_, err := s.db.ExecContext(ctx, `INSERT INTO sessions (`+sessionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		objectID(id), objectID(session.UserID), session.UserAgent, session.IP, stringList(session.Scopes), timestamp(session.CreatedAt),
+		timestamp(session.LastUsedAt), timestamp(session.ExpiresAt), optionalTime(session.RevokedAt))
+	if err != nil {
+		return err
+	}
+	session.ID = id
+	return nil
+}
+
+func (s *Store) GetSession(ctx context.Context, id bson.ObjectID) (*models.Session, error) {
+	session, err := scanSession(s.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = ?`, objectID(id)))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return session, nil
+}
+
+func (s *Store) ListActiveSessions(ctx context.Context, userID bson.ObjectID) ([]models.Session, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+sessionColumns+` FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ? ORDER BY last_used_at DESC`,
+		objectID(userID), timestamp(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sessions []models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+func (s *Store) TouchSession(ctx context.Context, id bson.ObjectID, usedAt, expiresAt time.Time) error {
+	if expiresAt.IsZero() {
+		return affected(s.db.ExecContext(ctx, `UPDATE sessions SET last_used_at = ? WHERE id = ?`,
+			timestamp(usedAt), objectID(id)))
+	}
+	return affected(s.db.ExecContext(ctx, `UPDATE sessions SET last_used_at = ?, expires_at = ? WHERE id = ?`,
+		timestamp(usedAt), timestamp(expiresAt), objectID(id)))
+}
+
+func (s *Store) RevokeSession(ctx context.Context, id bson.ObjectID) error {
+	return affected(s.db.ExecContext(ctx, `UPDATE sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`,
+		timestamp(time.Now()), objectID(id)))
+}
+
+func (s *Store) RevokeUserSessions(ctx context.Context, userID bson.ObjectID) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`,
+		timestamp(time.Now()), objectID(userID))
+	return err
+}
+
+func scanSession(row scanner) (*models.Session, error) {
+	var session models.Session
+	err := row.Scan((*objectID)(&session.ID), (*objectID)(&session.UserID), &session.UserAgent, &session.IP, (*stringList)(&session.Scopes),
+		(*timestamp)(&session.CreatedAt), (*timestamp)(&session.LastUsedAt), (*timestamp)(&session.ExpiresAt),
+		nullTimestamp{&session.RevokedAt})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
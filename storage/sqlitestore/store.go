@@ -0,0 +1,121 @@
+// Package sqlitestore implements the storage interfaces on an embedded SQLite
+// database, to run the aggregator without a MongoDB server. The schema is
+// created and upgraded by the migrations of the migrations directory.
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Store implements storage.Store
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the database file at path, creating it when needed, and applies
+// the pending migrations
+func Open(ctx context.Context, path string) (*Store, error) {
+	// write transactions take the lock when they begin, so concurrent writers
+	// wait on the busy timeout instead of failing when they upgrade a read lock
+	params := url.Values{}
+	params.Set("_foreign_keys", "on")
+	params.Set("_journal_mode", "WAL")
+	params.Set("_busy_timeout", "5000")
+	params.Set("_txlock", "immediate")
+	db, err := sql.Open("sqlite3", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *Store) Close(ctx context.Context) error {
+	return s.db.Close()
+}
+
+// withTx runs fn in a transaction, committed when fn returns nil
+func (s *Store) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// isUniqueViolation reports whether err comes from a unique index or primary key
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
+
+// notFound translates the error of a query that returned no row
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	return err
+}
+
+// affected returns storage.ErrNotFound when the statement changed no row
+func affected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// where builds the WHERE clause of a query from its conditions
+type where struct {
+	conditions []string
+	args       []any
+}
+
+func (w *where) add(condition string, args ...any) {
+	w.conditions = append(w.conditions, condition)
+	w.args = append(w.args, args...)
+}
+
+func (w *where) String() string {
+	if len(w.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conditions, " AND ")
+}
+
+// placeholders returns "?, ?, ..." for n values
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+var _ storage.Store = (*Store)(nil)
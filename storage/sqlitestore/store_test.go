@@ -0,0 +1,70 @@
+package sqlitestore
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/storagetest"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func open(t *testing.T, path string) *Store {
+	t.Helper()
+	s, err := Open(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close(context.Background()) })
+	return s
+}
+
+func TestStore(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Store {
+		return open(t, filepath.Join(t.TempDir(), "rss.db"))
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied := func(s *Store) int {
+		t.Helper()
+		var n int
+		if err := s.db.QueryRowContext(ctx, `SELECT count(*) FROM schema_migrations`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	path := filepath.Join(t.TempDir(), "rss.db")
+	s := open(t, path)
+	if n := applied(s); n != len(files) {
+		t.Fatalf("%d migrations recorded on a fresh database, want %d", n, len(files))
+	}
+	feed := models.Feed{URL: "https://example.com/feed.xml", UserID: bson.NewObjectID()}
+	if err := s.CreateFeed(ctx, &feed); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// opening the database again finds every migration applied
+	s = open(t, path)
+	if n := applied(s); n != len(files) {
+		t.Errorf("%d migrations recorded after reopening, want %d", n, len(files))
+	}
+	if err := migrate(ctx, s.db); err != nil {
+		t.Errorf("running the migrations again: %v", err)
+	}
+	if got, err := s.GetFeed(ctx, feed.ID); err != nil || got.URL != feed.URL {
+		t.Errorf("feed after reopening = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,66 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const tokenColumns = `id, user_id, family_id, replaced_by, expires_at, revoked_at, created_at`
+
+func (s *Store) SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return saveRefreshToken(ctx, s.db, token)
+}
+
+func saveRefreshToken(ctx context.Context, db execer, token *models.RefreshToken) error {
+	_, err := db.ExecContext(ctx, `INSERT INTO refresh_tokens (`+tokenColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		token.ID, objectID(token.UserID), token.FamilyID, token.ReplacedBy, timestamp(token.ExpiresAt),
+		optionalTime(token.RevokedAt), timestamp(token.CreatedAt))
+	if isUniqueViolation(err) {
+		return storage.ErrDuplicate
+	}
+	return err
+}
+
+func (s *Store) GetRefreshToken(ctx context.Context, id string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := s.db.QueryRowContext(ctx, `SELECT `+tokenColumns+` FROM refresh_tokens WHERE id = ?`, id).Scan(
+		&token.ID, (*objectID)(&token.UserID), &token.FamilyID, &token.ReplacedBy, (*timestamp)(&token.ExpiresAt),
+		nullTimestamp{&token.RevokedAt}, (*timestamp)(&token.CreatedAt))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (s *Store) RotateRefreshToken(ctx context.Context, id string, next *models.RefreshToken) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		err := affected(tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = ?, replaced_by = ? WHERE id = ? AND revoked_at IS NULL`,
+			timestamp(time.Now()), next.ID, id))
+		if err != nil {
+			return err
+		}
+		return saveRefreshToken(ctx, tx, next)
+	})
+}
+
+func (s *Store) RevokeRefreshToken(ctx context.Context, id string) error {
+	return affected(s.db.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`,
+		timestamp(time.Now()), id))
+}
+
+func (s *Store) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`,
+		timestamp(time.Now()), familyID)
+	return err
+}
+
+func (s *Store) RevokeUserRefreshTokens(ctx context.Context, userID bson.ObjectID) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`,
+		timestamp(time.Now()), objectID(userID))
+	return err
+}
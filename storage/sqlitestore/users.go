@@ -0,0 +1,66 @@
+package sqlitestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const userColumns = `id, username, email, password, roles, email_verified, email_verified_at, created_at, updated_at`
+
+func (s *Store) CreateUser(ctx context.Context, user *models.Auth) error {
+	id := bson.NewObjectID()
+	_, err := s.db.ExecContext(ctx, `INSERT INTO auths (`+userColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		objectID(id), user.Username, user.Email, user.Password, stringList(user.Roles), user.EmailVerified, optionalTime(user.EmailVerifiedAt),
+		timestamp(user.CreatedAt), timestamp(user.UpdatedAt))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return storage.ErrDuplicate
+		}
+		return err
+	}
+	user.ID = id
+	return nil
+}
+
+func (s *Store) GetUserByID(ctx context.Context, id bson.ObjectID) (*models.Auth, error) {
+	return s.findUser(ctx, `id = ?`, objectID(id))
+}
+
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (*models.Auth, error) {
+	return s.findUser(ctx, `email = ?`, email)
+}
+
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (*models.Auth, error) {
+	return s.findUser(ctx, `username = ?`, username)
+}
+
+func (s *Store) UpdatePassword(ctx context.Context, id bson.ObjectID, passwordHash string) error {
+	return affected(s.db.ExecContext(ctx, `UPDATE auths SET password = ?, updated_at = ? WHERE id = ?`,
+		passwordHash, timestamp(time.Now()), objectID(id)))
+}
+
+func (s *Store) MarkEmailVerified(ctx context.Context, id bson.ObjectID) error {
+	now := timestamp(time.Now())
+	return affected(s.db.ExecContext(ctx, `UPDATE auths SET email_verified = 1, email_verified_at = ?, updated_at = ? WHERE id = ?`,
+		now, now, objectID(id)))
+}
+
+func (s *Store) SetUserRoles(ctx context.Context, id bson.ObjectID, roles []string) error {
+	return affected(s.db.ExecContext(ctx, `UPDATE auths SET roles = ?, updated_at = ? WHERE id = ?`,
+		stringList(roles), timestamp(time.Now()), objectID(id)))
+}
+
+func (s *Store) findUser(ctx context.Context, condition string, args ...any) (*models.Auth, error) {
+	var user models.Auth
+	err := s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM auths WHERE `+condition, args...).Scan(
+		(*objectID)(&user.ID), &user.Username, &user.Email, &user.Password, (*stringList)(&user.Roles),
+		&user.EmailVerified, nullTimestamp{&user.EmailVerifiedAt}, (*timestamp)(&user.CreatedAt), (*timestamp)(&user.UpdatedAt))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
@@ -0,0 +1,165 @@
+package sqlitestore
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// objectID stores a bson.ObjectID as its hex string, the zero id is stored as NULL
+type objectID bson.ObjectID
+
+func (id objectID) Value() (driver.Value, error) {
+	if bson.ObjectID(id).IsZero() {
+		return nil, nil
+	}
+	return bson.ObjectID(id).Hex(), nil
+}
+
+func (id *objectID) Scan(src any) error {
+	var hex string
+	switch v := src.(type) {
+	case nil:
+		*id = objectID{}
+		return nil
+	case string:
+		hex = v
+	case []byte:
+		hex = string(v)
+	default:
+		return fmt.Errorf("sqlitestore: cannot scan %T into an object id", src)
+	}
+	parsed, err := bson.ObjectIDFromHex(hex)
+	if err != nil {
+		return err
+	}
+	*id = objectID(parsed)
+	return nil
+}
+
+// objectIDs converts ids to query arguments
+func objectIDs(ids []bson.ObjectID) []any {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = objectID(id)
+	}
+	return args
+}
+
+// timeLayout has a fixed width, so the order of the stored text is the order of the times
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// timestamp stores a time as UTC text
+type timestamp time.Time
+
+func (t timestamp) Value() (driver.Value, error) {
+	return time.Time(t).UTC().Format(timeLayout), nil
+}
+
+func (t *timestamp) Scan(src any) error {
+	var text string
+	switch v := src.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("sqlitestore: cannot scan %T into a time", src)
+	}
+	parsed, err := time.Parse(timeLayout, text)
+	if err != nil {
+		return err
+	}
+	*t = timestamp(parsed)
+	return nil
+}
+
+// nullTimestamp stores an optional time, nil is stored as NULL
+type nullTimestamp struct {
+	Time **time.Time
+}
+
+func (t nullTimestamp) Value() (driver.Value, error) {
+	if *t.Time == nil {
+		return nil, nil
+	}
+	return timestamp(**t.Time).Value()
+}
+
+func (t nullTimestamp) Scan(src any) error {
+	if src == nil {
+		*t.Time = nil
+		return nil
+	}
+	var value timestamp
+	if err := value.Scan(src); err != nil {
+		return err
+	}
+	parsed := time.Time(value)
+	*t.Time = &parsed
+	return nil
+}
+
+// optionalTime returns the query argument of an optional time
+func optionalTime(t *time.Time) any {
+	return nullTimestamp{&t}
+}
+
+// nullString stores the empty string as NULL, for the columns of unique indexes
+type nullString string
+
+func (s nullString) Value() (driver.Value, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return string(s), nil
+}
+
+func (s *nullString) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*s = ""
+	case string:
+		*s = nullString(v)
+	case []byte:
+		*s = nullString(v)
+	default:
+		return fmt.Errorf("sqlitestore: cannot scan %T into a string", src)
+	}
+	return nil
+}
+
+// stringList stores a list of strings as a JSON array
+type stringList []string
+
+func (l stringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(l))
+	return string(data), err
+}
+
+func (l *stringList) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("sqlitestore: cannot scan %T into a list", src)
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		list = nil
+	}
+	*l = list
+	return nil
+}
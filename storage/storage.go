@@ -0,0 +1,253 @@
+// Package storage defines the repositories the handlers, services and scraper
+// use, so the database behind them can be swapped. The mongostore package
+// implements them on MongoDB, the sqlitestore package on an embedded SQLite
+// database and the memory package keeps everything in memory.
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrNotFound  = errors.New("storage: not found")
+	ErrDuplicate = errors.New("storage: already exists")
+)
+
+// Store is a complete storage backend
+type Store interface {
+	UserStore
+	ProfileStore
+	FeedStore
+	PostStore
+	TokenStore
+	SessionStore
+	PasswordResetStore
+	EmailVerificationStore
+	MFAStore
+	APIKeyStore
+	IdentityStore
+	// Ping reports whether the backend can serve requests
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+// UserStore keeps the accounts users register and log in with
+type UserStore interface {
+	CreateUser(ctx context.Context, user *models.Auth) error
+	GetUserByID(ctx context.Context, id bson.ObjectID) (*models.Auth, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.Auth, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.Auth, error)
+	// UpdatePassword replaces the password hash of the user
+	UpdatePassword(ctx context.Context, id bson.ObjectID, passwordHash string) error
+	// MarkEmailVerified records that the user confirmed their email
+	MarkEmailVerified(ctx context.Context, id bson.ObjectID) error
+	SetUserRoles(ctx context.Context, id bson.ObjectID, roles []string) error
+}
+
+// ProfileStore keeps the user profiles of the /users endpoints
+type ProfileStore interface {
+	// CreateProfiles inserts the profiles and sets their ids
+	CreateProfiles(ctx context.Context, profiles []models.User) error
+	FindProfilesByEmail(ctx context.Context, email string) ([]models.User, error)
+	UpdateProfile(ctx context.Context, id bson.ObjectID, update ProfileUpdate) error
+}
+
+// FeedStore keeps the feeds and who follows them
+type FeedStore interface {
+	CreateFeed(ctx context.Context, feed *models.Feed) error
+	GetFeed(ctx context.Context, id bson.ObjectID) (*models.Feed, error)
+	GetFeedByURL(ctx context.Context, userID bson.ObjectID, url string) (*models.Feed, error)
+	// FindFeedByURL returns the oldest feed with the url, whoever created it
+	FindFeedByURL(ctx context.Context, url string) (*models.Feed, error)
+	ListFeedsByUser(ctx context.Context, userID bson.ObjectID) ([]models.Feed, error)
+	// DeleteFeed also deletes the follows and posts of the feed and the
+	// states of its posts
+	DeleteFeed(ctx context.Context, id bson.ObjectID) error
+
+	// NextFeedsToFetch returns the feeds fetched the longest time ago, never fetched ones first
+	NextFeedsToFetch(ctx context.Context, limit int) ([]models.Feed, error)
+	// MarkFeedFetched records a 200 or 304 response and the validators to send next time
+	MarkFeedFetched(ctx context.Context, id bson.ObjectID, status int, etag, lastModified string) error
+	// MarkFeedFailed records a failed fetch, status is 0 when no response was received
+	MarkFeedFailed(ctx context.Context, id bson.ObjectID, status int) error
+	// FillFeedInfo sets the title and site link of the feed when they are empty
+	FillFeedInfo(ctx context.Context, id bson.ObjectID, title, siteLink string) error
+
+	// FollowFeed makes the user follow the feed, following it twice is not an error
+	FollowFeed(ctx context.Context, userID, feedID bson.ObjectID) (*models.FeedFollow, error)
+	ListFollows(ctx context.Context, userID bson.ObjectID) ([]models.FeedFollow, error)
+	DeleteFollow(ctx context.Context, userID, followID bson.ObjectID) error
+	IsFollowing(ctx context.Context, userID, feedID bson.ObjectID) (bool, error)
+	// HasOtherFollowers reports whether users other than userID follow the feed
+	HasOtherFollowers(ctx context.Context, feedID, userID bson.ObjectID) (bool, error)
+	FollowedFeedIDs(ctx context.Context, userID bson.ObjectID) ([]bson.ObjectID, error)
+	// ListFollowedFeeds returns the feeds the user follows, which are their
+	// subscriptions whoever created the feeds
+	ListFollowedFeeds(ctx context.Context, userID bson.ObjectID) ([]models.Feed, error)
+}
+
+// PostStore keeps the posts and their per-user state. A user can see the posts
+// of the feeds they follow and the posts they created.
+type PostStore interface {
+	CreatePost(ctx context.Context, post *models.Post) error
+	GetPost(ctx context.Context, id bson.ObjectID) (*models.Post, error)
+	GetVisiblePost(ctx context.Context, userID, id bson.ObjectID) (*models.Post, error)
+	UpdatePost(ctx context.Context, id bson.ObjectID, update PostUpdate) error
+	DeletePost(ctx context.Context, id bson.ObjectID) error
+
+	ListPosts(ctx context.Context, userID bson.ObjectID, query PostListQuery) ([]models.Post, error)
+	SearchPosts(ctx context.Context, userID bson.ObjectID, query PostSearchQuery) ([]ScoredPost, error)
+	// GetVisiblePosts returns the posts among ids the user can see
+	GetVisiblePosts(ctx context.Context, userID bson.ObjectID, ids []bson.ObjectID) ([]models.Post, error)
+
+	// UpsertFeedPost inserts an item of a feed, or updates it in place when it
+	// changed since the last fetch, see ItemKey
+	UpsertFeedPost(ctx context.Context, post models.Post) (inserted, updated bool, err error)
+
+	SetPostsRead(ctx context.Context, userID bson.ObjectID, posts []models.Post, read bool) error
+	// MarkFeedRead marks read the posts of a feed published at or before a
+	// date, or all of them when before is nil, and returns how many they are
+	MarkFeedRead(ctx context.Context, userID, feedID bson.ObjectID, before *time.Time) (int64, error)
+	SetPostStarred(ctx context.Context, userID bson.ObjectID, post models.Post, starred bool) error
+	GetPostStates(ctx context.Context, userID bson.ObjectID, postIDs []bson.ObjectID) (map[bson.ObjectID]models.PostState, error)
+	CountUnreadPosts(ctx context.Context, userID bson.ObjectID, feedIDs []bson.ObjectID) (map[bson.ObjectID]int64, error)
+}
+
+// TokenStore keeps the refresh tokens issued to the users
+type TokenStore interface {
+	SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshToken(ctx context.Context, id string) (*models.RefreshToken, error)
+	// RotateRefreshToken revokes the token id, records that next replaced it
+	// and saves next. It returns ErrNotFound when id is already revoked, so a
+	// token can only be rotated once.
+	RotateRefreshToken(ctx context.Context, id string, next *models.RefreshToken) error
+	// RevokeRefreshToken returns ErrNotFound when the token is missing or already revoked
+	RevokeRefreshToken(ctx context.Context, id string) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID bson.ObjectID) error
+}
+
+// ProfileUpdate renames a profile and adds AgeIncrement to its age
+type ProfileUpdate struct {
+	Name         string
+	AgeIncrement int
+}
+
+// SessionStore keeps the sessions opened by the logins of the users
+type SessionStore interface {
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, id bson.ObjectID) (*models.Session, error)
+	// ListActiveSessions returns the sessions of the user that are neither
+	// revoked nor expired, the most recently used first
+	ListActiveSessions(ctx context.Context, userID bson.ObjectID) ([]models.Session, error)
+	// TouchSession records a use of the session, and extends it until
+	// expiresAt when expiresAt is not zero
+	TouchSession(ctx context.Context, id bson.ObjectID, usedAt, expiresAt time.Time) error
+	// RevokeSession returns ErrNotFound when the session is missing or already revoked
+	RevokeSession(ctx context.Context, id bson.ObjectID) error
+	RevokeUserSessions(ctx context.Context, userID bson.ObjectID) error
+}
+
+// PasswordResetStore keeps the pending password resets by token hash
+type PasswordResetStore interface {
+	CreatePasswordReset(ctx context.Context, reset *models.PasswordReset) error
+	// ConsumePasswordReset marks the reset as used along with every other
+	// pending reset of its user. It returns ErrNotFound when the reset is
+	// missing, used or expired.
+	ConsumePasswordReset(ctx context.Context, id string) (*models.PasswordReset, error)
+}
+
+// EmailVerificationStore keeps the pending email verifications by token hash
+type EmailVerificationStore interface {
+	CreateEmailVerification(ctx context.Context, verification *models.EmailVerification) error
+	// ConsumeEmailVerification marks the verification as used along with every
+	// other pending verification of its user. It returns ErrNotFound when the
+	// verification is missing, used or expired.
+	ConsumeEmailVerification(ctx context.Context, id string) (*models.EmailVerification, error)
+}
+
+// MFAStore keeps the second factor of the users by user id
+type MFAStore interface {
+	GetMFA(ctx context.Context, userID bson.ObjectID) (*models.MFA, error)
+	// SaveMFA creates or replaces the second factor of the user
+	SaveMFA(ctx context.Context, mfa *models.MFA) error
+	DeleteMFA(ctx context.Context, userID bson.ObjectID) error
+	// UseTOTPStep records a successful code of the time step and clears the
+	// failed attempts. It returns ErrNotFound when a code of this or a later
+	// step was already used.
+	UseTOTPStep(ctx context.Context, userID bson.ObjectID, step int64) error
+	// UseRecoveryCode removes the recovery code hash and clears the failed
+	// attempts. It returns ErrNotFound when the code is not an unused one.
+	UseRecoveryCode(ctx context.Context, userID bson.ObjectID, codeHash string) error
+	// RecordMFAFailure counts a wrong code given for the user
+	RecordMFAFailure(ctx context.Context, userID bson.ObjectID) error
+}
+
+// APIKeyStore keeps the API keys of the users
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	// ListAPIKeys returns the keys of the user that are not revoked, the newest first
+	ListAPIKeys(ctx context.Context, userID bson.ObjectID) ([]models.APIKey, error)
+	TouchAPIKey(ctx context.Context, id bson.ObjectID, usedAt time.Time) error
+	// RevokeAPIKey returns ErrNotFound when the user has no such key that is not revoked yet
+	RevokeAPIKey(ctx context.Context, userID, id bson.ObjectID) error
+	RevokeUserAPIKeys(ctx context.Context, userID bson.ObjectID) error
+}
+
+// IdentityStore keeps the accounts of the users at OpenID Connect providers
+type IdentityStore interface {
+	// CreateIdentity returns ErrDuplicate when the account is already linked
+	CreateIdentity(ctx context.Context, identity *models.Identity) error
+	GetIdentity(ctx context.Context, issuer, subject string) (*models.Identity, error)
+}
+
+// PostUpdate holds the fields to change, nil fields are kept
+type PostUpdate struct {
+	Title       *string
+	Description *string
+	Link        *string
+}
+
+// PostFilter are the filters shared by the list and search queries
+type PostFilter struct {
+	FeedID   *bson.ObjectID
+	Category string
+	Author   string
+	Since    *time.Time
+	Until    *time.Time
+}
+
+// PostCursor is the position of the last post of a page
+type PostCursor struct {
+	PublishedAt time.Time     `json:"p"`
+	ID          bson.ObjectID `json:"id"`
+}
+
+// PostListQuery is a page of posts ordered by (published_at, _id)
+type PostListQuery struct {
+	PostFilter
+	Limit       int
+	Ascending   bool
+	After       *PostCursor
+	UnreadOnly  bool
+	StarredOnly bool
+}
+
+type PostSearchQuery struct {
+	PostFilter
+	Text   string
+	Limit  int
+	Offset int
+}
+
+// ScoredPost is a search result, the higher the score the more relevant the post
+type ScoredPost struct {
+	models.Post `bson:",inline"`
+	Score       float64 `bson:"score" json:"score"`
+}
@@ -0,0 +1,415 @@
+// Package storagetest checks that a storage backend behaves like the others,
+// every backend runs the same tests from its own test file.
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/models"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Run runs the tests against the stores returned by open, each test gets a
+// new empty store
+func Run(t *testing.T, open func(t *testing.T) storage.Store) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s storage.Store)
+	}{
+		{"NotFound", testNotFound},
+		{"Duplicates", testDuplicates},
+		{"Feeds", testFeeds},
+		{"UpsertFeedPost", testUpsertFeedPost},
+		{"ListPostsPagination", testListPostsPagination},
+		{"PostStates", testPostStates},
+		{"DeleteFeed", testDeleteFeed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, open(t))
+		})
+	}
+}
+
+// published is the date of the test posts, whole seconds are kept by every backend
+var published = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func testNotFound(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	missing := bson.NewObjectID()
+	title := "title"
+	checks := map[string]error{
+		"GetUserByID":    second(s.GetUserByID(ctx, missing)),
+		"GetUserByEmail": second(s.GetUserByEmail(ctx, "missing@example.com")),
+		"GetFeed":        second(s.GetFeed(ctx, missing)),
+		"FindFeedByURL":  second(s.FindFeedByURL(ctx, "https://example.com/missing")),
+		"DeleteFeed":     s.DeleteFeed(ctx, missing),
+		"DeleteFollow":   s.DeleteFollow(ctx, missing, missing),
+		"GetPost":        second(s.GetPost(ctx, missing)),
+		"UpdatePost":     s.UpdatePost(ctx, missing, storage.PostUpdate{Title: &title}),
+		"DeletePost":     s.DeletePost(ctx, missing),
+		"GetSession":     second(s.GetSession(ctx, missing)),
+		"RevokeSession":  s.RevokeSession(ctx, missing),
+		"GetMFA":         second(s.GetMFA(ctx, missing)),
+		"GetIdentity":    second(s.GetIdentity(ctx, "https://issuer", "missing")),
+	}
+	for name, err := range checks {
+		if !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("%s: %v, want ErrNotFound", name, err)
+		}
+	}
+}
+
+func second[T any](_ T, err error) error {
+	return err
+}
+
+func testDuplicates(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	user := newUser(t, s, "jane")
+	for name, dup := range map[string]models.Auth{
+		"email":    {Username: "other", Email: user.Email},
+		"username": {Username: user.Username, Email: "other@example.com"},
+	} {
+		dup.CreatedAt, dup.UpdatedAt = time.Now(), time.Now()
+		if err := s.CreateUser(ctx, &dup); !errors.Is(err, storage.ErrDuplicate) {
+			t.Errorf("user with the same %s: %v, want ErrDuplicate", name, err)
+		}
+	}
+
+	identity := models.Identity{UserID: user.ID, Issuer: "https://issuer", Subject: "1", CreatedAt: time.Now()}
+	if err := s.CreateIdentity(ctx, &identity); err != nil {
+		t.Fatal(err)
+	}
+	again := identity
+	again.ID, again.UserID = bson.ObjectID{}, bson.NewObjectID()
+	if err := s.CreateIdentity(ctx, &again); !errors.Is(err, storage.ErrDuplicate) {
+		t.Errorf("identity linked twice: %v, want ErrDuplicate", err)
+	}
+	got, err := s.GetIdentity(ctx, identity.Issuer, identity.Subject)
+	if err != nil || got.UserID != user.ID {
+		t.Errorf("GetIdentity = %v, %v, want the identity of the first user", got, err)
+	}
+}
+
+func testFeeds(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	alice, bob := bson.NewObjectID(), bson.NewObjectID()
+	first := newFeed(t, s, alice, "https://example.com/shared.xml")
+	newFeed(t, s, bob, "https://example.com/shared.xml")
+	own := newFeed(t, s, bob, "https://example.com/bob.xml")
+
+	found, err := s.FindFeedByURL(ctx, "https://example.com/shared.xml")
+	if err != nil || found.ID != first.ID {
+		t.Errorf("FindFeedByURL = %v, %v, want the oldest feed %s", found, err, first.ID)
+	}
+	byUser, err := s.GetFeedByURL(ctx, bob, "https://example.com/shared.xml")
+	if err != nil || byUser.UserID != bob {
+		t.Errorf("GetFeedByURL of bob = %v, %v", byUser, err)
+	}
+
+	follow, err := s.FollowFeed(ctx, bob, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := s.FollowFeed(ctx, bob, first.ID)
+	if err != nil || again.ID != follow.ID {
+		t.Errorf("following twice = %v, %v, want the existing follow %s", again, err, follow.ID)
+	}
+	if _, err := s.FollowFeed(ctx, bob, own.ID); err != nil {
+		t.Fatal(err)
+	}
+	followed, err := s.ListFollowedFeeds(ctx, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := feedIDs(followed); len(ids) != 2 || ids[0] != first.ID || ids[1] != own.ID {
+		t.Errorf("ListFollowedFeeds = %v, want %s and %s", ids, first.ID, own.ID)
+	}
+	if following, err := s.IsFollowing(ctx, alice, first.ID); err != nil || following {
+		t.Errorf("IsFollowing of alice = %v, %v, want false", following, err)
+	}
+	if others, err := s.HasOtherFollowers(ctx, first.ID, alice); err != nil || !others {
+		t.Errorf("HasOtherFollowers = %v, %v, want true", others, err)
+	}
+
+	if err := s.DeleteFollow(ctx, alice, follow.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("DeleteFollow of another user: %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteFollow(ctx, bob, follow.ID); err != nil {
+		t.Fatal(err)
+	}
+	if others, err := s.HasOtherFollowers(ctx, first.ID, alice); err != nil || others {
+		t.Errorf("HasOtherFollowers after the unfollow = %v, %v, want false", others, err)
+	}
+}
+
+func feedIDs(feeds []models.Feed) []bson.ObjectID {
+	ids := make([]bson.ObjectID, 0, len(feeds))
+	for _, feed := range feeds {
+		ids = append(ids, feed.ID)
+	}
+	return ids
+}
+
+func testUpsertFeedPost(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	feed := newFeed(t, s, bson.NewObjectID(), "https://example.com/feed.xml")
+	other := newFeed(t, s, bson.NewObjectID(), "https://example.com/other.xml")
+	item := models.Post{FeedID: feed.ID, GUID: "1", Title: "Title", Link: "https://example.com/1", PublishedAt: published}
+	edited := item
+	edited.Title = "Edited"
+	inOther := item
+	inOther.FeedID = other.ID
+	byLink := models.Post{FeedID: feed.ID, Title: "No guid", Link: "https://example.com/2"}
+
+	steps := []struct {
+		name              string
+		post              models.Post
+		inserted, updated bool
+	}{
+		{"new item", item, true, false},
+		{"same item", item, false, false},
+		{"edited item", edited, false, true},
+		{"edited item again", edited, false, false},
+		{"same guid in another feed", inOther, true, false},
+		{"item without guid", byLink, true, false},
+		{"same link and title", byLink, false, false},
+	}
+	for _, step := range steps {
+		inserted, updated, err := s.UpsertFeedPost(ctx, step.post)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if inserted != step.inserted || updated != step.updated {
+			t.Errorf("%s: inserted %v updated %v, want %v %v", step.name, inserted, updated, step.inserted, step.updated)
+		}
+	}
+
+	posts := feedPosts(t, s, feed)
+	if len(posts) != 2 {
+		t.Fatalf("%d posts in the feed, want 2", len(posts))
+	}
+	for _, post := range posts {
+		switch post.Link {
+		case item.Link:
+			if post.Title != "Edited" || !post.PublishedAt.Equal(published) {
+				t.Errorf("edited item = %q published %v", post.Title, post.PublishedAt)
+			}
+		case byLink.Link:
+			// undated items get the time they were first seen
+			if post.PublishedAt.IsZero() {
+				t.Error("undated item has no published date")
+			}
+		}
+	}
+}
+
+// feedPosts returns every post of the feed, as seen by its owner
+func feedPosts(t *testing.T, s storage.Store, feed models.Feed) []models.Post {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := s.FollowFeed(ctx, feed.UserID, feed.ID); err != nil {
+		t.Fatal(err)
+	}
+	posts, err := s.ListPosts(ctx, feed.UserID, storage.PostListQuery{
+		PostFilter: storage.PostFilter{FeedID: &feed.ID},
+		Limit:      100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return posts
+}
+
+func testListPostsPagination(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	userID := bson.NewObjectID()
+	feed := newFeed(t, s, userID, "https://example.com/feed.xml")
+	hidden := newFeed(t, s, bson.NewObjectID(), "https://example.com/hidden.xml")
+	if _, err := s.FollowFeed(ctx, userID, feed.ID); err != nil {
+		t.Fatal(err)
+	}
+	var want []models.Post
+	for i := range 7 {
+		// pairs of posts published at the same time are ordered by id
+		post := models.Post{
+			FeedID:      feed.ID,
+			GUID:        fmt.Sprint(i),
+			Title:       fmt.Sprint("Post ", i),
+			Link:        fmt.Sprint("https://example.com/", i),
+			PublishedAt: published.Add(time.Duration(i/2) * time.Hour),
+		}
+		if _, _, err := s.UpsertFeedPost(ctx, post); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := s.UpsertFeedPost(ctx, models.Post{FeedID: hidden.ID, GUID: post.GUID, Title: "Hidden", PublishedAt: post.PublishedAt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	own := models.Post{UserID: userID, Title: "Own", Link: "https://example.com/own", PublishedAt: published}
+	if err := s.CreatePost(ctx, &own); err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, feedPosts(t, s, feed)...)
+	want = append(want, own)
+	sort.Slice(want, func(i, j int) bool { return comparePosts(want[i], want[j]) < 0 })
+
+	for _, ascending := range []bool{true, false} {
+		var got []models.Post
+		query := storage.PostListQuery{Limit: 3, Ascending: ascending}
+		for range len(want) {
+			page, err := s.ListPosts(ctx, userID, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, page...)
+			if len(page) < query.Limit {
+				break
+			}
+			last := page[len(page)-1]
+			query.After = &storage.PostCursor{PublishedAt: last.PublishedAt, ID: last.ID}
+		}
+		expected := append([]models.Post(nil), want...)
+		if !ascending {
+			for i, j := 0, len(expected)-1; i < j; i, j = i+1, j-1 {
+				expected[i], expected[j] = expected[j], expected[i]
+			}
+		}
+		if fmt.Sprint(postIDs(got)) != fmt.Sprint(postIDs(expected)) {
+			t.Errorf("ascending %v: pages returned %v, want %v", ascending, postIDs(got), postIDs(expected))
+		}
+	}
+}
+
+func comparePosts(a, b models.Post) int {
+	if c := a.PublishedAt.Compare(b.PublishedAt); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
+
+func postIDs(posts []models.Post) []bson.ObjectID {
+	ids := make([]bson.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	return ids
+}
+
+func testPostStates(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	userID := bson.NewObjectID()
+	feed := newFeed(t, s, userID, "https://example.com/feed.xml")
+	for i := range 3 {
+		post := models.Post{FeedID: feed.ID, GUID: fmt.Sprint(i), Title: "Post", PublishedAt: published.Add(time.Duration(i) * time.Hour)}
+		if _, _, err := s.UpsertFeedPost(ctx, post); err != nil {
+			t.Fatal(err)
+		}
+	}
+	posts := feedPosts(t, s, feed)
+	sort.Slice(posts, func(i, j int) bool { return comparePosts(posts[i], posts[j]) < 0 })
+	if err := s.SetPostStarred(ctx, userID, posts[2], true); err != nil {
+		t.Fatal(err)
+	}
+
+	before := published.Add(time.Hour)
+	if n, err := s.MarkFeedRead(ctx, userID, feed.ID, &before); err != nil || n != 2 {
+		t.Errorf("MarkFeedRead = %d, %v, want the 2 posts published until %v", n, err, before)
+	}
+	counts, err := s.CountUnreadPosts(ctx, userID, []bson.ObjectID{feed.ID})
+	if err != nil || counts[feed.ID] != 1 {
+		t.Errorf("CountUnreadPosts = %v, %v, want 1", counts, err)
+	}
+	list := func(query storage.PostListQuery) []bson.ObjectID {
+		query.Limit = 10
+		page, err := s.ListPosts(ctx, userID, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return postIDs(page)
+	}
+	if ids := list(storage.PostListQuery{UnreadOnly: true}); len(ids) != 1 || ids[0] != posts[2].ID {
+		t.Errorf("unread posts = %v, want %s", ids, posts[2].ID)
+	}
+	if ids := list(storage.PostListQuery{StarredOnly: true}); len(ids) != 1 || ids[0] != posts[2].ID {
+		t.Errorf("starred posts = %v, want %s", ids, posts[2].ID)
+	}
+
+	// marking read keeps the star, and marking a read post again is harmless
+	if n, err := s.MarkFeedRead(ctx, userID, feed.ID, nil); err != nil || n != 3 {
+		t.Errorf("MarkFeedRead = %d, %v, want 3", n, err)
+	}
+	states, err := s.GetPostStates(ctx, userID, postIDs(posts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, post := range posts {
+		state := states[post.ID]
+		if !state.Read || state.ReadAt == nil || state.Starred != (i == 2) {
+			t.Errorf("state of post %d = read %v at %v, starred %v", i, state.Read, state.ReadAt, state.Starred)
+		}
+	}
+	if ids := list(storage.PostListQuery{UnreadOnly: true}); len(ids) != 0 {
+		t.Errorf("unread posts = %v, want none", ids)
+	}
+}
+
+func testDeleteFeed(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	userID := bson.NewObjectID()
+	feed := newFeed(t, s, userID, "https://example.com/feed.xml")
+	if _, _, err := s.UpsertFeedPost(ctx, models.Post{FeedID: feed.ID, GUID: "1", Title: "Post", PublishedAt: published}); err != nil {
+		t.Fatal(err)
+	}
+	posts := feedPosts(t, s, feed)
+	if err := s.SetPostsRead(ctx, userID, posts, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteFeed(ctx, feed.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetPost(ctx, posts[0].ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("post of the deleted feed: %v, want ErrNotFound", err)
+	}
+	if follows, err := s.ListFollows(ctx, userID); err != nil || len(follows) != 0 {
+		t.Errorf("follows of the deleted feed = %v, %v", follows, err)
+	}
+	if states, err := s.GetPostStates(ctx, userID, postIDs(posts)); err != nil || len(states) != 0 {
+		t.Errorf("states of the deleted posts = %v, %v", states, err)
+	}
+}
+
+func newUser(t *testing.T, s storage.Store, name string) models.Auth {
+	t.Helper()
+	user := models.Auth{
+		Username:  name,
+		Email:     name + "@example.com",
+		Password:  "hash",
+		Roles:     []string{models.RoleUser},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.CreateUser(context.Background(), &user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func newFeed(t *testing.T, s storage.Store, userID bson.ObjectID, url string) models.Feed {
+	t.Helper()
+	// feeds are ordered by creation, which must differ at the precision of every backend
+	time.Sleep(2 * time.Millisecond)
+	feed := models.Feed{URL: url, UserID: userID, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := s.CreateFeed(context.Background(), &feed); err != nil {
+		t.Fatal(err)
+	}
+	return feed
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Aym-Aymen777/RSS-Aggregator/config"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/memory"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/mongostore"
+	"github.com/Aym-Aymen777/RSS-Aggregator/storage/sqlitestore"
+)
+
+// openStore opens the storage backend selected by STORAGE_DRIVER
+func openStore(cfg *config.StorageConfig) (storage.Store, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	switch cfg.Driver {
+	case "mongo":
+		client := connectDB()
+		store, err := mongostore.New(ctx, client.Database("rssagg"))
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "sqlite":
+		store, err := sqlitestore.Open(ctx, cfg.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("✅ Opened SQLite database %s\n", cfg.SQLitePath)
+		return store, nil
+	case "memory":
+		return memory.New(), nil
+	}
+	return nil, fmt.Errorf("unknown STORAGE_DRIVER %q, expected mongo, sqlite or memory", cfg.Driver)
+}
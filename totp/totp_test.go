@@ -0,0 +1,72 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the test vectors of RFC 6238 appendix B
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	// the RFC gives 8 digit codes, the 6 digit ones are their last digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},          // 94287082
+		{1111111109, "081804"},  // 07081804
+		{1111111111, "050471"},  // 14050471
+		{1234567890, "005924"},  // 89005924
+		{2000000000, "279037"},  // 69279037
+		{20000000000, "353130"}, // 65353130
+	}
+	for _, tt := range tests {
+		code, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tt.code {
+			t.Errorf("code at %d = %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestValidateSkewWindow(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	current := Step(now)
+	for offset := int64(-3); offset <= 3; offset++ {
+		code, err := Code(secret, current+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step, ok := Validate(secret, code, now)
+		inWindow := offset >= -Skew && offset <= Skew
+		if ok != inWindow {
+			t.Errorf("code of step %+d accepted = %v, want %v", offset, ok, inWindow)
+		}
+		if ok && step != current+offset {
+			t.Errorf("code of step %+d validated as step %d, want %d", offset, step, current+offset)
+		}
+	}
+}
+
+func TestValidateRejectsMalformedCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+	if _, ok := Validate(rfcSecret, " 287082\n", now); !ok {
+		t.Error("code with surrounding spaces refused")
+	}
+	for _, code := range []string{"", "28708", "2870820", "94287082", "abcdef"} {
+		if _, ok := Validate(rfcSecret, code, now); ok {
+			t.Errorf("Validate accepted %q", code)
+		}
+	}
+	if _, ok := Validate("not base32!", "287082", now); ok {
+		t.Error("Validate accepted a code for an invalid secret")
+	}
+}
@@ -19,4 +19,16 @@ func RespondWithJSON(w http.ResponseWriter, code int, payload any) {
 
 func RespondWithError(w http.ResponseWriter, code int, message string) {
 	RespondWithJSON(w, code, map[string]string{"error": message})
-} 
\ No newline at end of file
+} 
+// RespondWithJSONFeed writes a JSON Feed document with its registered media type
+func RespondWithJSONFeed(w http.ResponseWriter, code int, feed any) {
+	dat, err := json.Marshal(feed)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("HTTP 500: Internal Server Error"))
+		return
+	}
+	w.Header().Add("Content-Type", "application/feed+json")
+	w.WriteHeader(code)
+	w.Write(dat)
+}